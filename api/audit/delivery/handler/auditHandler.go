@@ -0,0 +1,74 @@
+package auditHandler
+
+import (
+	"net/http"
+	"strconv"
+
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	auditUsecase "github.com/ahsansandiah/dpo-test/api/audit/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	res "github.com/ahsansandiah/dpo-test/packages/json"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+)
+
+type Audit struct {
+	log     log.Log
+	Json    res.Json
+	Usecase auditDomainInterface.AuditUsecase
+}
+
+func NewAuditHandler(mgr manager.Manager) auditDomainInterface.AuditHandler {
+	handler := new(Audit)
+	handler.Usecase = auditUsecase.NewAuditUsecase(mgr)
+	handler.Json = mgr.GetJson()
+
+	return handler
+}
+
+func (h *Audit) GetAll() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		queryParams := r.URL.Query()
+		limitStr := queryParams.Get("limit")
+		limit := paginateHelper.DefaultLimit
+		if limitStr != "" {
+			l, err := strconv.Atoi(limitStr)
+			if err == nil {
+				limit = l
+			}
+		}
+
+		// without an id the events of every entity of the type are listed
+		var entityID int64
+		if entityIDStr := queryParams.Get("id"); entityIDStr != "" {
+			id, err := strconv.ParseInt(entityIDStr, 10, 64)
+			if err != nil {
+				h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+				return
+			}
+			entityID = id
+		}
+
+		filter := &auditDomainEntity.AuditFilter{
+			Entity:   queryParams.Get("entity"),
+			EntityID: entityID,
+			LIMIT:    limit,
+			Cursor:   queryParams.Get("cursor"),
+		}
+
+		result, err := h.Usecase.GetAll(ctx, filter)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.PaginateResponse(w, r, "Success get data", result.Event, result.Paginate)
+	})
+}
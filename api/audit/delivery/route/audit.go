@@ -0,0 +1,15 @@
+package auditRoute
+
+import (
+	auditHandler "github.com/ahsansandiah/dpo-test/api/audit/delivery/handler"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewAuditRoute(mgr manager.Manager, route *mux.Router) {
+	auditHandler := auditHandler.NewAuditHandler(mgr)
+	can := mgr.GetMiddleware().RequirePermission
+
+	route.Handle("/audit", can(auditDomainEntity.PermissionAuditRead)(auditHandler.GetAll())).Methods("GET")
+}
@@ -0,0 +1,14 @@
+package auditRoutes
+
+import (
+	auditRoute "github.com/ahsansandiah/dpo-test/api/audit/delivery/route"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewRoutes(r *mux.Router, mgr manager.Manager) {
+	apiAuth := r.PathPrefix("").Subrouter()
+	apiAuth.Use(mgr.GetMiddleware().CheckToken, mgr.GetMiddleware().RateLimit("api"))
+
+	auditRoute.NewAuditRoute(mgr, apiAuth)
+}
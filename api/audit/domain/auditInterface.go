@@ -0,0 +1,24 @@
+package auditDomainInterface
+
+import (
+	"context"
+	"net/http"
+
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+)
+
+type AuditHandler interface {
+	GetAll() http.Handler
+}
+
+type AuditUsecase interface {
+	GetAll(ctx context.Context, filter *auditDomainEntity.AuditFilter) (*auditDomainEntity.AuditListResponse, error)
+	// Record stores a mutation of an entity with the actor and request id of
+	// ctx. Inside a transaction it is stored together with the mutation.
+	Record(ctx context.Context, entityType string, entityID int64, action string, before, after interface{}) error
+}
+
+type AuditRepository interface {
+	GetAll(ctx context.Context, filter *auditDomainEntity.AuditFilter) ([]auditDomainEntity.AuditEvent, error)
+	Create(ctx context.Context, event *auditDomainEntity.AuditEvent) error
+}
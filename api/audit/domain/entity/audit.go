@@ -0,0 +1,86 @@
+package auditDomainEntity
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+)
+
+const PermissionAuditRead = "audit:read"
+
+// Entity types recorded in the audit log.
+const (
+	EntityCustomer = "customer"
+	EntityOrder    = "order"
+	EntityUser     = "user"
+)
+
+var Entities = []string{EntityCustomer, EntityOrder, EntityUser}
+
+// Actions recorded in the audit log.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// AuditEvent is one mutation of an entity. Before and After are snapshots of
+// the entity, Before is null on create and After on delete. ActorID is null
+// for anonymous requests such as registering a user.
+type AuditEvent struct {
+	ID            int64                  `json:"id"`
+	EntityType    string                 `json:"entity_type"`
+	EntityID      int64                  `json:"entity_id"`
+	Action        string                 `json:"action"`
+	ActorID       *int64                 `json:"actor_id"`
+	ActorUsername string                 `json:"actor_username"`
+	RequestID     string                 `json:"request_id"`
+	Before        json.RawMessage        `json:"before"`
+	After         json.RawMessage        `json:"after"`
+	Changes       map[string]AuditChange `json:"changes"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// AuditChange is the value of a field before and after a mutation.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type AuditListResponse struct {
+	Event    []AuditEvent             `json:"audit"`
+	Paginate *paginateHelper.Paginate `json:"paginate"`
+}
+
+type AuditFilter struct {
+	Entity   string                 `json:"entity"`
+	EntityID int64                  `json:"id"`
+	LIMIT    int                    `json:"limit"`
+	Cursor   string                 `json:"cursor"`
+	Keyset   *paginateHelper.Cursor `json:"-"`
+}
+
+func (r *AuditFilter) Validate() error {
+	v := validationHelper.New()
+
+	if v.Required("entity", r.Entity) {
+		v.Check(isEntity(r.Entity), "entity", validationHelper.RuleOneOf, fmt.Sprintf("entity must be one of %s", strings.Join(Entities, ", ")))
+	}
+
+	return v.Err()
+}
+
+func isEntity(entity string) bool {
+	for _, known := range Entities {
+		if entity == known {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package auditRepository
+
+import (
+	"context"
+	"encoding/json"
+
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+)
+
+type Audit struct {
+	DB  transactionDatabase.Transaction
+	log log.Log
+	cfg *config.Config
+}
+
+func NewAuditRepository(mgr manager.Manager) auditDomainInterface.AuditRepository {
+	repo := new(Audit)
+	repo.DB = mgr.GetTransaction()
+	repo.log = mgr.GetLog()
+	repo.cfg = mgr.GetConfig()
+
+	return repo
+}
+
+func (r *Audit) GetAll(ctx context.Context, filter *auditDomainEntity.AuditFilter) ([]auditDomainEntity.AuditEvent, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `SELECT a.id, a.entity_type, a.entity_id, a.action, a.actor_id, COALESCE(a.actor_username, ''), COALESCE(a.request_id, ''), a.before_data, a.after_data, a.changes, a.created_at
+              FROM audit_events a
+              WHERE a.entity_type = ?`
+	args := []interface{}{filter.Entity}
+
+	if filter.EntityID != 0 {
+		query += " AND a.entity_id = ?"
+		args = append(args, filter.EntityID)
+	}
+
+	backward := false
+	if filter.Keyset != nil {
+		condition, keysetArgs := filter.Keyset.Condition("a")
+		query += condition
+		args = append(args, keysetArgs...)
+		backward = filter.Keyset.Backward
+	}
+
+	query += paginateHelper.OrderBy("a", paginateHelper.SortByID, backward)
+	query += " LIMIT ?"
+	args = append(args, filter.LIMIT)
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []auditDomainEntity.AuditEvent{}
+	for rows.Next() {
+		var event auditDomainEntity.AuditEvent
+		var before, after, changes []byte
+		if err := rows.Scan(&event.ID, &event.EntityType, &event.EntityID, &event.Action, &event.ActorID, &event.ActorUsername, &event.RequestID, &before, &after, &changes, &event.CreatedAt); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+
+		event.Before = before
+		event.After = after
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &event.Changes); err != nil {
+				r.log.ErrorLog(ctx, err)
+				return nil, err
+			}
+		}
+
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *Audit) Create(ctx context.Context, event *auditDomainEntity.AuditEvent) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	query := `INSERT INTO audit_events (entity_type, entity_id, action, actor_id, actor_username, request_id, before_data, after_data, changes)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.DB.Executor(ctx).ExecContext(ctx, query, event.EntityType, event.EntityID, event.Action, event.ActorID, nullString(event.ActorUsername), nullString(event.RequestID), nullJSON(event.Before), nullJSON(event.After), changes)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+// nullJSON stores a missing snapshot as NULL instead of an empty string, which
+// is not a valid JSON value.
+func nullJSON(value json.RawMessage) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return string(value)
+}
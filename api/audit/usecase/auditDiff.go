@@ -0,0 +1,71 @@
+package auditUsecase
+
+import (
+	"encoding/json"
+	"reflect"
+
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+)
+
+// snapshot serializes an entity for the audit log, a nil entity has no
+// snapshot.
+func snapshot(entity interface{}) (json.RawMessage, error) {
+	if entity == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "null" {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+// diff compares two snapshots field by field and returns the fields that
+// changed. A missing snapshot counts as an object without fields, so every
+// field of a created or deleted entity is a change. Nested values such as the
+// items of an order are compared as a whole.
+func diff(before, after json.RawMessage) (map[string]auditDomainEntity.AuditChange, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]auditDomainEntity.AuditChange{}
+	for name, value := range beforeFields {
+		if other, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = auditDomainEntity.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = auditDomainEntity.AuditChange{After: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func fields(data json.RawMessage) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if len(data) == 0 {
+		return values, nil
+	}
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,53 @@
+package auditUsecase
+
+import (
+	"testing"
+
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type auditedEntity struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Email string   `json:"email,omitempty"`
+}
+
+func TestDiffListsChangedFields(t *testing.T) {
+	before, _ := snapshot(&auditedEntity{ID: 1, Name: "John", Tags: []string{"a"}})
+	after, _ := snapshot(&auditedEntity{ID: 1, Name: "Jane", Tags: []string{"a"}, Email: "jane@example.com"})
+
+	changes, err := diff(before, after)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]auditDomainEntity.AuditChange{
+		"name":  {Before: "John", After: "Jane"},
+		"email": {After: "jane@example.com"},
+	}, changes)
+}
+
+func TestDiffComparesNestedValuesAsAWhole(t *testing.T) {
+	before, _ := snapshot(&auditedEntity{ID: 1, Tags: []string{"a"}})
+	after, _ := snapshot(&auditedEntity{ID: 1, Tags: []string{"a", "b"}})
+
+	changes, err := diff(before, after)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, changes["tags"].After)
+	assert.Len(t, changes, 1)
+}
+
+func TestDiffOfDeletedEntity(t *testing.T) {
+	var deleted *auditedEntity
+	before, _ := snapshot(&auditedEntity{ID: 1, Name: "John"})
+	after, err := snapshot(deleted)
+	assert.NoError(t, err)
+	assert.Nil(t, after)
+
+	changes, err := diff(before, after)
+
+	assert.NoError(t, err)
+	assert.Equal(t, auditDomainEntity.AuditChange{Before: "John"}, changes["name"])
+	assert.Equal(t, auditDomainEntity.AuditChange{Before: float64(1)}, changes["id"])
+}
@@ -0,0 +1,132 @@
+package auditUsecase
+
+import (
+	"context"
+
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	auditRepository "github.com/ahsansandiah/dpo-test/api/audit/repository"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+)
+
+type AuditUsecase struct {
+	log  log.Log
+	cfg  *config.Config
+	repo auditDomainInterface.AuditRepository
+}
+
+func NewAuditUsecase(mgr manager.Manager) auditDomainInterface.AuditUsecase {
+	usecase := new(AuditUsecase)
+	usecase.log = mgr.GetLog()
+	usecase.cfg = mgr.GetConfig()
+	usecase.repo = auditRepository.NewAuditRepository(mgr)
+
+	return usecase
+}
+
+func (u *AuditUsecase) GetAll(ctx context.Context, filter *auditDomainEntity.AuditFilter) (*auditDomainEntity.AuditListResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.LIMIT = paginateHelper.ValidateLimit(filter.LIMIT)
+
+	if filter.Cursor != "" {
+		cursor, err := paginateHelper.ParseCursor(filter.Cursor, u.cfg.PaginateCursorSecret)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != paginateHelper.SortByID {
+			return nil, errorHelper.ErrorInvalidCursor
+		}
+		filter.Keyset = cursor
+	}
+
+	// fetch one extra event to know whether another page exists
+	limit := filter.LIMIT
+	filter.LIMIT = limit + 1
+	events, err := u.repo.GetAll(ctx, filter)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching audit events")
+	}
+	filter.LIMIT = limit
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	var first, last *paginateHelper.Cursor
+	if len(events) > 0 {
+		firstCursor := paginateHelper.NewCursor(paginateHelper.SortByID, events[0].CreatedAt, events[0].ID)
+		lastCursor := paginateHelper.NewCursor(paginateHelper.SortByID, events[len(events)-1].CreatedAt, events[len(events)-1].ID)
+		first, last = &firstCursor, &lastCursor
+	}
+
+	paginate, err := paginateHelper.NewPaginate(u.cfg.PaginateCursorSecret, filter.Keyset, hasMore, first, last)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	result := &auditDomainEntity.AuditListResponse{
+		Event:    events,
+		Paginate: paginate,
+	}
+
+	return result, nil
+}
+
+func (u *AuditUsecase) Record(ctx context.Context, entityType string, entityID int64, action string, before, after interface{}) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	event := &auditDomainEntity.AuditEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+	}
+
+	if principal, ok := principalAuth.FromContext(ctx); ok {
+		event.ActorID = &principal.UserID
+		event.ActorUsername = principal.Username
+	}
+
+	// the request id is set by the InitLog middleware
+	if requestID, ok := ctx.Value(config.ContextKey("id")).(string); ok {
+		event.RequestID = requestID
+	}
+
+	var err error
+	if event.Before, err = snapshot(before); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	if event.After, err = snapshot(after); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	if event.Changes, err = diff(event.Before, event.After); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return u.repo.Create(ctx, event)
+}
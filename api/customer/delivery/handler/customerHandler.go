@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	customerDomainInterface "github.com/ahsansandiah/dpo-test/api/customer/domain"
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 	customerUsecase "github.com/ahsansandiah/dpo-test/api/customer/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	res "github.com/ahsansandiah/dpo-test/packages/json"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
@@ -31,128 +36,216 @@ func NewCustomerHandler(mgr manager.Manager) customerDomainInterface.CustomerHan
 
 func (h *Customer) GetAll() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		queryParams := r.URL.Query()
-		limit := queryParams.Get("limit")
-		if limit == "" {
-			limit = "10"
+		limitStr := queryParams.Get("limit")
+		limit := paginateHelper.DefaultLimit
+		if limitStr != "" {
+			l, err := strconv.Atoi(limitStr)
+			if err == nil {
+				limit = l
+			}
 		}
 
-		isActiveParams := queryParams.Get("is_active")
-		isActive := false
-		if isActiveParams == "true" {
-			isActive = true
+		// is_active is tri-state, without it customers are not filtered on it
+		var isActive *bool
+		if isActiveParams := queryParams.Get("is_active"); isActiveParams != "" {
+			active, err := strconv.ParseBool(isActiveParams)
+			if err != nil {
+				h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidIsActive)
+				return
+			}
+			isActive = &active
+		}
+
+		deleted, err := softDeleteHelper.ParseScope(queryParams.Get("include_deleted"), queryParams.Get("only_deleted"))
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
 		}
 
 		filter := &customerDomainEntity.CustomerFilter{
+			Q:           strings.TrimSpace(queryParams.Get("q")),
 			FullName:    queryParams.Get("full_name"),
 			Email:       queryParams.Get("email"),
 			PhoneNumber: queryParams.Get("phone_number"),
 			IsActive:    isActive,
 			LIMIT:       limit,
+			Cursor:      queryParams.Get("cursor"),
+			SortBy:      queryParams.Get("sort_by"),
+			Deleted:     deleted,
 		}
 
 		result, err := h.Usecase.GetAll(ctx, filter)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success get data", result)
+		h.Json.PaginateResponse(w, r, "Success get data", result.Customer, result.Paginate)
 	})
 }
 
 func (h *Customer) Delete() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		customerIDStr := mux.Vars(r)["id"]
 		customerID, err := strconv.ParseInt(customerIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		err = h.Usecase.Delete(ctx, customerID)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, fmt.Sprintf("Customer with ID %d deleted successfully", customerID), nil)
+		h.Json.SuccessResponse(w, r, fmt.Sprintf("Customer with ID %d deleted successfully", customerID), nil)
 	})
 }
 
 func (h *Customer) GetByID() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		customerIDStr := mux.Vars(r)["id"]
 		customerID, err := strconv.ParseInt(customerIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		customer, err := h.Usecase.GetByID(ctx, customerID)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success get data", customer)
+		h.Json.SuccessResponse(w, r, "Success get data", customer)
 	})
 }
 
 func (h *Customer) Update() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		customerIDStr := mux.Vars(r)["id"]
 		customerID, err := strconv.ParseInt(customerIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		var req *customerDomainEntity.CustomerRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
 		customer, err := h.Usecase.Update(ctx, customerID, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success updated", customer)
+		h.Json.SuccessResponse(w, r, "Success updated", customer)
 	})
 }
 
 func (h *Customer) Create() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		var req *customerDomainEntity.CustomerRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
 		customer, err := h.Usecase.Create(ctx, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.CreatedResponse(w, r, "Success created", customer)
+	})
+}
+
+func (h *Customer) Activate() http.Handler {
+	return h.setActive(true, "Success activated")
+}
+
+func (h *Customer) Deactivate() http.Handler {
+	return h.setActive(false, "Success deactivated")
+}
+
+func (h *Customer) setActive(active bool, message string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		customerIDStr := mux.Vars(r)["id"]
+		customerID, err := strconv.ParseInt(customerIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		customer, err := h.Usecase.SetActive(ctx, customerID, active)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, message, customer)
+	})
+}
+
+func (h *Customer) Restore() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		customerIDStr := mux.Vars(r)["id"]
+		customerID, err := strconv.ParseInt(customerIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		customer, err := h.Usecase.Restore(ctx, customerID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success created", customer)
+		h.Json.SuccessResponse(w, r, "Success restored", customer)
 	})
 }
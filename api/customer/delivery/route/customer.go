@@ -2,16 +2,21 @@ package customerRoute
 
 import (
 	customerHandler "github.com/ahsansandiah/dpo-test/api/customer/delivery/handler"
+	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
 	"github.com/gorilla/mux"
 )
 
 func NewCustomerRoute(mgr manager.Manager, route *mux.Router) {
 	customerHandler := customerHandler.NewCustomerHandler(mgr)
+	can := mgr.GetMiddleware().RequirePermission
 
-	route.Handle("/customers", customerHandler.GetAll()).Methods("GET")
-	route.Handle("/customers/{id}", customerHandler.Delete()).Methods("DELETE")
-	route.Handle("/customers/{id}", customerHandler.GetByID()).Methods("GET")
-	route.Handle("/customers/{id}", customerHandler.Update()).Methods("PUT")
-	route.Handle("/customers", customerHandler.Create()).Methods("POST")
+	route.Handle("/customers", can(customerDomainEntity.PermissionCustomerRead)(customerHandler.GetAll())).Methods("GET")
+	route.Handle("/customers/{id}", can(customerDomainEntity.PermissionCustomerDelete)(customerHandler.Delete())).Methods("DELETE")
+	route.Handle("/customers/{id}", can(customerDomainEntity.PermissionCustomerRead)(customerHandler.GetByID())).Methods("GET")
+	route.Handle("/customers/{id}", can(customerDomainEntity.PermissionCustomerWrite)(customerHandler.Update())).Methods("PUT")
+	route.Handle("/customers", can(customerDomainEntity.PermissionCustomerWrite)(customerHandler.Create())).Methods("POST")
+	route.Handle("/customers/{id}/activate", can(customerDomainEntity.PermissionCustomerWrite)(customerHandler.Activate())).Methods("POST")
+	route.Handle("/customers/{id}/deactivate", can(customerDomainEntity.PermissionCustomerWrite)(customerHandler.Deactivate())).Methods("POST")
+	route.Handle("/customers/{id}/restore", can(customerDomainEntity.PermissionCustomerRestore)(customerHandler.Restore())).Methods("POST")
 }
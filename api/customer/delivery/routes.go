@@ -8,7 +8,7 @@ import (
 
 func NewRoutes(r *mux.Router, mgr manager.Manager) {
 	apiAuth := r.PathPrefix("").Subrouter()
-	apiAuth.Use(mgr.GetMiddleware().CheckToken)
+	apiAuth.Use(mgr.GetMiddleware().CheckToken, mgr.GetMiddleware().RateLimit("api"), mgr.GetMiddleware().Idempotency)
 
 	customerRoute.NewCustomerRoute(mgr, apiAuth)
 }
@@ -3,6 +3,7 @@ package customerDomainInterface
 import (
 	"context"
 	"net/http"
+	"time"
 
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 )
@@ -13,20 +14,31 @@ type CustomerHandler interface {
 	GetByID() http.Handler
 	Update() http.Handler
 	Create() http.Handler
+	Activate() http.Handler
+	Deactivate() http.Handler
+	Restore() http.Handler
 }
 
 type CustomerUsecase interface {
-	GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error)
+	GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) (*customerDomainEntity.CustomerListResponse, error)
 	Delete(ctx context.Context, ID int64) error
 	GetByID(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error)
 	Update(ctx context.Context, ID int64, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error)
 	Create(ctx context.Context, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error)
+	SetActive(ctx context.Context, ID int64, active bool) (*customerDomainEntity.Customer, error)
+	Restore(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error)
 }
 
 type CustomerRepository interface {
 	GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error)
+	Search(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error)
 	GetById(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error)
-	Delete(ctx context.Context, ID int64) error
+	GetByIdForUpdate(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error)
+	GetDeletedByIdForUpdate(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error)
+	SetActive(ctx context.Context, ID int64, active bool) error
+	Delete(ctx context.Context, ID int64, deletedAt time.Time) error
+	Restore(ctx context.Context, ID int64) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	Update(ctx context.Context, ID int64, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error)
 	Create(ctx context.Context, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error)
 }
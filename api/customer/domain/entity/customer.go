@@ -3,7 +3,27 @@ package customerDomainEntity
 import (
 	"time"
 
-	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+)
+
+const (
+	PermissionCustomerRead    = "customers:read"
+	PermissionCustomerWrite   = "customers:write"
+	PermissionCustomerDelete  = "customers:delete"
+	PermissionCustomerRestore = "customers:restore"
+)
+
+// Domain events of customers, published through the outbox with the customer
+// as payload.
+const (
+	EventAggregateCustomer = "customer"
+
+	EventCustomerCreated  = "CustomerCreated"
+	EventCustomerUpdated  = "CustomerUpdated"
+	EventCustomerDeleted  = "CustomerDeleted"
+	EventCustomerRestored = "CustomerRestored"
 )
 
 type Customer struct {
@@ -15,6 +35,19 @@ type Customer struct {
 	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// DeletedAt is only set on deleted customers, which only admins can list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Score and Highlights are only set on search results
+	Score      float64             `json:"score,omitempty"`
+	Highlights []CustomerHighlight `json:"highlights,omitempty"`
+}
+
+// CustomerHighlight tells which field of a customer matched a search and how,
+// the matched part of the value is wrapped in <em> tags.
+type CustomerHighlight struct {
+	Field   string `json:"field"`
+	Match   string `json:"match"`
+	Snippet string `json:"snippet"`
 }
 
 type CustomerRequest struct {
@@ -24,30 +57,49 @@ type CustomerRequest struct {
 	Email       string `json:"email"`
 }
 
+type CustomerListResponse struct {
+	Customer []Customer               `json:"customer"`
+	Paginate *paginateHelper.Paginate `json:"paginate"`
+}
+
 type CustomerFilter struct {
-	FullName    string `json:"full_name"`
-	PhoneNumber string `json:"phone_number"`
-	Email       string `json:"email"`
-	IsActive    bool   `json:"is_active"`
-	LIMIT       string `json:"limit"`
+	Q           string                 `json:"q"`
+	FullName    string                 `json:"full_name"`
+	PhoneNumber string                 `json:"phone_number"`
+	Email       string                 `json:"email"`
+	IsActive    *bool                  `json:"is_active"`
+	LIMIT       int                    `json:"limit"`
+	Cursor      string                 `json:"cursor"`
+	SortBy      string                 `json:"sort_by"`
+	Keyset      *paginateHelper.Cursor `json:"-"`
+	Deleted     softDeleteHelper.Scope `json:"-"`
+}
+
+// Validate checks the search query, the other filters need no validation.
+func (r *CustomerFilter) Validate() error {
+	v := validationHelper.New()
+	v.Length("q", r.Q, 2, 100)
+
+	return v.Err()
 }
 
 func (r *CustomerRequest) Validate() error {
-	if r.FullName == "" {
-		return errorHelper.ErrorFullNameIsRequired
-	}
+	v := validationHelper.New()
 
-	if r.Address == "" {
-		return errorHelper.ErrorAddressIsRequired
+	if v.Required("full_name", r.FullName) {
+		v.Length("full_name", r.FullName, 1, 255)
 	}
 
-	if r.PhoneNumber == "" {
-		return errorHelper.ErrorPhoneNumberIsRequired
+	v.Required("address", r.Address)
+
+	if v.Required("phone_number", r.PhoneNumber) {
+		v.Phone("phone_number", r.PhoneNumber)
 	}
 
-	if r.Email == "" {
-		return errorHelper.ErrorEmailIsRequired
+	if v.Required("email", r.Email) {
+		v.Length("email", r.Email, 1, 255)
+		v.Email("email", r.Email)
 	}
 
-	return nil
+	return v.Err()
 }
@@ -3,24 +3,29 @@ package customerRepository
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	customerDomainInterface "github.com/ahsansandiah/dpo-test/api/customer/domain"
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
 )
 
 type Customer struct {
-	DB  *sql.DB
+	DB  transactionDatabase.Transaction
 	log log.Log
 	cfg *config.Config
 }
 
 func NewCustomerRepository(mgr manager.Manager) customerDomainInterface.CustomerRepository {
 	repo := new(Customer)
-	repo.DB = mgr.GetDB()
+	repo.DB = mgr.GetTransaction()
 	repo.log = mgr.GetLog()
 	repo.cfg = mgr.GetConfig()
 
@@ -28,42 +33,90 @@ func NewCustomerRepository(mgr manager.Manager) customerDomainInterface.Customer
 }
 
 func (r *Customer) GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error) {
-	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE deleted_at IS NULL"
-	var args []interface{}
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
 
-	if filter.FullName != "" {
-		query += " AND full_name = ?"
-		args = append(args, filter.FullName)
+	query := "SELECT c.id, c.full_name, c.address, c.phone_number, c.email, c.is_active, c.created_at, c.updated_at, c.deleted_at FROM customers c WHERE TRUE"
+	conditions, args := filterConditions(filter)
+	query += conditions
+
+	backward := false
+	if filter.Keyset != nil {
+		condition, keysetArgs := filter.Keyset.Condition("c")
+		query += condition
+		args = append(args, keysetArgs...)
+		backward = filter.Keyset.Backward
 	}
 
-	if filter.PhoneNumber != "" {
-		query += " AND phone_number = ?"
-		args = append(args, filter.PhoneNumber)
+	query += paginateHelper.OrderBy("c", filter.SortBy, backward)
+	query += " LIMIT ?"
+	args = append(args, filter.LIMIT)
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	if filter.Email != "" {
-		query += " AND email = ?"
-		args = append(args, filter.Email)
+	customers := []customerDomainEntity.Customer{}
+	for rows.Next() {
+		var customer customerDomainEntity.Customer
+		if err := rows.Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt, &customer.DeletedAt); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
 	}
 
-	if filter.FullName == "" && filter.Email == "" && filter.PhoneNumber == "" {
-		query = "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE deleted_at IS NULL"
+	return customers, nil
+}
+
+// Search returns the candidates of a search: customers the FULLTEXT index
+// finds, best full text relevance first. Score holds the full text relevance.
+// Only when the index finds nobody, for instance for a fragment of a phone
+// number, the searched fields are scanned with LIKE, those candidates have a
+// Score of zero.
+func (r *Customer) Search(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	match := "MATCH(c.full_name, c.email, c.phone_number) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	customers, err := r.search(ctx, filter, match+" AS relevance", match, filter.Q, filter.Q)
+	if err != nil || len(customers) > 0 {
+		return customers, err
 	}
 
-	query += " LIMIT ?"
+	contains := "%" + escapeLike(filter.Q) + "%"
+	return r.search(ctx, filter, "0 AS relevance", "c.full_name LIKE ? OR c.email LIKE ? OR c.phone_number LIKE ?", contains, contains, contains)
+}
+
+func (r *Customer) search(ctx context.Context, filter *customerDomainEntity.CustomerFilter, relevance, condition string, args ...interface{}) ([]customerDomainEntity.Customer, error) {
+	query := "SELECT c.id, c.full_name, c.address, c.phone_number, c.email, c.is_active, c.created_at, c.updated_at, c.deleted_at, " + relevance + " FROM customers c WHERE TRUE"
+	query += " AND (" + condition + ")"
+
+	conditions, filterArgs := filterConditions(filter)
+	query += conditions
+	args = append(args, filterArgs...)
+
+	query += " ORDER BY relevance DESC, c.id DESC LIMIT ?"
 	args = append(args, filter.LIMIT)
 
-	rows, err := r.DB.Query(query, args...)
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var customers []customerDomainEntity.Customer
+	customers := []customerDomainEntity.Customer{}
 	for rows.Next() {
 		var customer customerDomainEntity.Customer
-		if err := rows.Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt); err != nil {
+		if err := rows.Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt, &customer.DeletedAt, &customer.Score); err != nil {
 			r.log.ErrorLog(ctx, err)
 			return nil, err
 		}
@@ -77,11 +130,51 @@ func (r *Customer) GetAll(ctx context.Context, filter *customerDomainEntity.Cust
 	return customers, nil
 }
 
+// filterConditions returns the WHERE fragments, prefixed with AND, of the
+// deleted scope and the exact match filters.
+func filterConditions(filter *customerDomainEntity.CustomerFilter) (string, []interface{}) {
+	conditions := filter.Deleted.Condition("c")
+	var args []interface{}
+
+	if filter.FullName != "" {
+		conditions += " AND c.full_name = ?"
+		args = append(args, filter.FullName)
+	}
+
+	if filter.PhoneNumber != "" {
+		conditions += " AND c.phone_number = ?"
+		args = append(args, filter.PhoneNumber)
+	}
+
+	if filter.Email != "" {
+		conditions += " AND c.email = ?"
+		args = append(args, filter.Email)
+	}
+
+	if filter.IsActive != nil {
+		conditions += " AND c.is_active = ?"
+		args = append(args, *filter.IsActive)
+	}
+
+	return conditions, args
+}
+
+// escapeLike escapes the LIKE wildcards in value.
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
 func (r *Customer) GetById(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	customer := customerDomainEntity.Customer{}
 
-	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ?"
-	err := r.DB.QueryRow(query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ? AND deleted_at IS NULL"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorCustomerNotFound
+	}
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -90,16 +183,86 @@ func (r *Customer) GetById(ctx context.Context, ID int64) (*customerDomainEntity
 	return &customer, nil
 }
 
-func (r *Customer) Delete(ctx context.Context, ID int64) error {
-	stmt, err := r.DB.Prepare("UPDATE customers SET deleted_at = ? WHERE id = ?")
+// GetByIdForUpdate reads a customer that is not deleted and, inside a
+// transaction, locks its row until the transaction ends.
+func (r *Customer) GetByIdForUpdate(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	customer := customerDomainEntity.Customer{}
+
+	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorCustomerNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+func (r *Customer) SetActive(ctx context.Context, ID int64, active bool) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE customers SET is_active = ? WHERE id = ?", active, ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetDeletedByIdForUpdate reads a deleted customer and, inside a
+// transaction, locks its row until the transaction ends.
+func (r *Customer) GetDeletedByIdForUpdate(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	customer := customerDomainEntity.Customer{}
+
+	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at, deleted_at FROM customers WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt, &customer.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorCustomerNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+func (r *Customer) Delete(ctx context.Context, ID int64, deletedAt time.Time) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	stmt, err := r.DB.Executor(ctx).PrepareContext(ctx, "UPDATE customers SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
 	}
 	defer stmt.Close()
 
-	// Set deleted_at to current timestamp
-	_, err = stmt.Exec(time.Now(), ID)
+	_, err = stmt.ExecContext(ctx, deletedAt, ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Customer) Restore(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE customers SET deleted_at = NULL WHERE id = ?", ID)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
@@ -108,9 +271,37 @@ func (r *Customer) Delete(ctx context.Context, ID int64) error {
 	return nil
 }
 
+// Purge hard deletes the customers deleted before the given time. Customers
+// that still have orders which are not purged themselves are kept, deleting
+// them would cascade to those orders.
+func (r *Customer) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `DELETE FROM customers WHERE deleted_at < ? AND NOT EXISTS (
+                SELECT 1 FROM orders o WHERE o.customer_id = customers.id AND (o.deleted_at IS NULL OR o.deleted_at >= ?)
+              )`
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, before, before)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return purged, nil
+}
+
 func (r *Customer) Update(ctx context.Context, ID int64, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	var customer customerDomainEntity.Customer
-	stmt, err := r.DB.PrepareContext(ctx, "UPDATE customers SET full_name = ?, address = ?, phone_number = ?, email = ? WHERE id = ?")
+	stmt, err := r.DB.Executor(ctx).PrepareContext(ctx, "UPDATE customers SET full_name = ?, address = ?, phone_number = ?, email = ? WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -126,7 +317,7 @@ func (r *Customer) Update(ctx context.Context, ID int64, request *customerDomain
 
 	// Query the updated customer
 	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ?"
-	err = r.DB.QueryRowContext(ctx, query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	err = r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -136,7 +327,10 @@ func (r *Customer) Update(ctx context.Context, ID int64, request *customerDomain
 }
 
 func (r *Customer) Create(ctx context.Context, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error) {
-	result, err := r.DB.ExecContext(ctx, "INSERT INTO customers (full_name, address, phone_number, email) VALUES (?, ?, ?, ?)", request.FullName, request.Address, request.PhoneNumber, request.Email)
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO customers (full_name, address, phone_number, email) VALUES (?, ?, ?, ?)", request.FullName, request.Address, request.PhoneNumber, request.Email)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -151,7 +345,7 @@ func (r *Customer) Create(ctx context.Context, request *customerDomainEntity.Cus
 
 	var customer customerDomainEntity.Customer
 	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ?"
-	err = r.DB.QueryRowContext(ctx, query, customerID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	err = r.DB.Executor(ctx).QueryRowContext(ctx, query, customerID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
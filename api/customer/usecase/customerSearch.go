@@ -0,0 +1,377 @@
+package customerUsecase
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
+)
+
+// searchCandidateLimit is how many full text candidates are ranked per search.
+const searchCandidateLimit = 200
+
+// How a field matched a search, best first.
+const (
+	MatchExact    = "exact"
+	MatchPrefix   = "prefix"
+	MatchContains = "contains"
+	MatchFuzzy    = "fuzzy"
+)
+
+var matchScores = map[string]float64{
+	MatchExact:    1,
+	MatchPrefix:   0.8,
+	MatchContains: 0.6,
+	MatchFuzzy:    0.4,
+}
+
+// searchFields are the searched fields with the weight of a match on them.
+var searchFields = []struct {
+	name   string
+	weight float64
+	value  func(*customerDomainEntity.Customer) string
+}{
+	{"full_name", 1, func(c *customerDomainEntity.Customer) string { return c.FullName }},
+	{"email", 0.9, func(c *customerDomainEntity.Customer) string { return c.Email }},
+	{"phone_number", 0.9, func(c *customerDomainEntity.Customer) string { return c.PhoneNumber }},
+}
+
+type fieldMatch struct {
+	kind  string
+	spans [][2]int
+}
+
+// rankSearchResults drops the candidates none of the fields really match,
+// highlights the matching fields and orders the rest by relevance. The Score
+// of a candidate is its full text relevance on input and its match score on
+// output, the full text relevance only breaks ties.
+func rankSearchResults(query string, candidates []customerDomainEntity.Customer) []customerDomainEntity.Customer {
+	query = normalizeQuery(query)
+
+	type ranked struct {
+		customer  customerDomainEntity.Customer
+		relevance float64
+	}
+
+	results := make([]ranked, 0, len(candidates))
+	for _, customer := range candidates {
+		relevance := customer.Score
+		customer.Score = 0
+		customer.Highlights = nil
+
+		for _, field := range searchFields {
+			value := field.value(&customer)
+			match := matchField(value, query)
+			if match == nil && field.name == "phone_number" {
+				match = matchPhone(value, query)
+			}
+			if match == nil {
+				continue
+			}
+
+			// the best field decides the score, every other matching field
+			// adds a little
+			score := matchScores[match.kind] * field.weight
+			if score > customer.Score {
+				customer.Score, score = score, customer.Score
+			}
+			customer.Score += score * 0.1
+
+			customer.Highlights = append(customer.Highlights, customerDomainEntity.CustomerHighlight{
+				Field:   field.name,
+				Match:   match.kind,
+				Snippet: highlight(value, match.spans),
+			})
+		}
+
+		if len(customer.Highlights) == 0 {
+			continue
+		}
+
+		customer.Score = math.Round(customer.Score*1000) / 1000
+		results = append(results, ranked{customer: customer, relevance: relevance})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].customer.Score != results[j].customer.Score {
+			return results[i].customer.Score > results[j].customer.Score
+		}
+		if results[i].relevance != results[j].relevance {
+			return results[i].relevance > results[j].relevance
+		}
+
+		return results[i].customer.ID > results[j].customer.ID
+	})
+
+	customers := make([]customerDomainEntity.Customer, 0, len(results))
+	for _, result := range results {
+		customers = append(customers, result.customer)
+	}
+
+	return customers
+}
+
+// normalizeQuery lower cases the query and collapses its white space.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// matchField matches the whole query against value first, then every word of
+// the query against the words of value. All query words must match, the
+// weakest of them decides the kind of the match.
+func matchField(value, query string) *fieldMatch {
+	lower := strings.ToLower(value)
+	match := matchLowerField(lower, query)
+	if match != nil && len(lower) != len(value) {
+		// lower casing changed the byte offsets, they can not be highlighted
+		match.spans = nil
+	}
+
+	return match
+}
+
+func matchLowerField(value, query string) *fieldMatch {
+	if value == query {
+		return &fieldMatch{kind: MatchExact, spans: [][2]int{{0, len(value)}}}
+	}
+
+	if index := strings.Index(value, query); index >= 0 {
+		kind := MatchContains
+		if isWordStart(value, index) {
+			kind = MatchPrefix
+		}
+
+		return &fieldMatch{kind: kind, spans: [][2]int{{index, index + len(query)}}}
+	}
+
+	words := splitWords(value)
+	match := &fieldMatch{kind: MatchPrefix}
+	for _, token := range strings.Fields(query) {
+		kind, span, ok := matchToken(value, words, token)
+		if !ok {
+			return nil
+		}
+
+		if matchScores[kind] < matchScores[match.kind] {
+			match.kind = kind
+		}
+		match.spans = append(match.spans, span)
+	}
+
+	return match
+}
+
+// matchToken finds the best match of a query word among the words of value.
+func matchToken(value string, words [][2]int, token string) (string, [2]int, bool) {
+	bestKind := ""
+	var bestSpan [2]int
+
+	for _, word := range words {
+		text := value[word[0]:word[1]]
+
+		kind := ""
+		span := word
+		switch {
+		case strings.HasPrefix(text, token):
+			kind = MatchPrefix
+			span = [2]int{word[0], word[0] + len(token)}
+		case strings.Contains(text, token):
+			kind = MatchContains
+			index := strings.Index(text, token)
+			span = [2]int{word[0] + index, word[0] + index + len(token)}
+		case isFuzzyMatch(text, token):
+			kind = MatchFuzzy
+		}
+
+		if kind != "" && (bestKind == "" || matchScores[kind] > matchScores[bestKind]) {
+			bestKind, bestSpan = kind, span
+		}
+	}
+
+	return bestKind, bestSpan, bestKind != ""
+}
+
+// isFuzzyMatch allows one typo in short words and two in longer ones, the
+// word is also compared cut to the length of the token so a misspelled
+// prefix still matches.
+func isFuzzyMatch(word, token string) bool {
+	tokenLength := len([]rune(token))
+	if tokenLength < 3 {
+		return false
+	}
+
+	allowed := 1
+	if tokenLength > 5 {
+		allowed = 2
+	}
+
+	if levenshtein(word, token) <= allowed {
+		return true
+	}
+
+	runes := []rune(word)
+	if len(runes) > tokenLength {
+		return levenshtein(string(runes[:tokenLength]), token) <= allowed
+	}
+
+	return false
+}
+
+// matchPhone compares only the digits, so "0812-345" finds "+62812345...".
+// A leading 0 of a local number stands for the country code.
+func matchPhone(value, query string) *fieldMatch {
+	queryDigits := digitsOf(query)
+	// queries that are mostly letters are no phone numbers
+	if len(queryDigits) < 3 || len(queryDigits)*2 < len(strings.ReplaceAll(query, " ", "")) {
+		return nil
+	}
+
+	local := strings.HasPrefix(queryDigits, "0")
+	queryDigits = strings.TrimLeft(queryDigits, "0")
+	if queryDigits == "" {
+		return nil
+	}
+
+	positions := []int{}
+	for i, r := range value {
+		if r >= '0' && r <= '9' {
+			positions = append(positions, i)
+		}
+	}
+	valueDigits := digitsOf(value)
+
+	index := strings.Index(valueDigits, queryDigits)
+	if index < 0 {
+		return nil
+	}
+
+	// the number starts right after the country code for a local query
+	numberStart := index == 0
+	if local {
+		numberStart = index > 0 && index <= 3
+	}
+
+	kind := MatchContains
+	if numberStart {
+		kind = MatchPrefix
+		if index+len(queryDigits) == len(valueDigits) {
+			kind = MatchExact
+		}
+	}
+
+	start := positions[index]
+	end := positions[index+len(queryDigits)-1] + 1
+
+	return &fieldMatch{kind: kind, spans: [][2]int{{start, end}}}
+}
+
+func digitsOf(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// splitWords returns the byte ranges of the words of value, anything that is
+// not a letter or a digit separates words.
+func splitWords(value string) [][2]int {
+	words := [][2]int{}
+	start := -1
+	for i, r := range value {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		}
+		if !isWordRune && start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, [2]int{start, len(value)})
+	}
+
+	return words
+}
+
+func isWordStart(value string, index int) bool {
+	if index == 0 {
+		return true
+	}
+
+	r := rune(value[index-1])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// highlight wraps the spans of value in <em> tags. The text is HTML escaped,
+// the <em> tags are the only markup of the result.
+func highlight(value string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return html.EscapeString(value)
+	}
+
+	sorted := make([][2]int, len(spans))
+	copy(sorted, spans)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+
+	var builder strings.Builder
+	position := 0
+	for _, span := range sorted {
+		if span[0] < position {
+			span[0] = position
+		}
+		if span[1] <= span[0] {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(value[position:span[0]]))
+		builder.WriteString("<em>")
+		builder.WriteString(html.EscapeString(value[span[0]:span[1]]))
+		builder.WriteString("</em>")
+		position = span[1]
+	}
+	builder.WriteString(html.EscapeString(value[position:]))
+
+	return builder.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,63 @@
+package customerUsecase
+
+import (
+	"testing"
+
+	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func searchCandidates() []customerDomainEntity.Customer {
+	return []customerDomainEntity.Customer{
+		{ID: 1, FullName: "Budi Santoso", Email: "budi@example.com", PhoneNumber: "+6281111111111", Score: 0.9},
+		{ID: 2, FullName: "Ahsan Sandiah", Email: "ahsan.sandiah@example.com", PhoneNumber: "+6281234567890", Score: 0.1},
+		{ID: 3, FullName: "Nur Ahsanul", Email: "nur@example.com", PhoneNumber: "+6282222222222", Score: 0.5},
+	}
+}
+
+func TestRankSearchResultsOrdersByMatch(t *testing.T) {
+	customers := rankSearchResults("Ahsan", searchCandidates())
+
+	assert.Len(t, customers, 2)
+	assert.Equal(t, int64(2), customers[0].ID)
+	assert.Equal(t, int64(3), customers[1].ID)
+	assert.Equal(t, customerDomainEntity.CustomerHighlight{Field: "full_name", Match: MatchPrefix, Snippet: "<em>Ahsan</em> Sandiah"}, customers[0].Highlights[0])
+	assert.Equal(t, customerDomainEntity.CustomerHighlight{Field: "email", Match: MatchPrefix, Snippet: "<em>ahsan</em>.sandiah@example.com"}, customers[0].Highlights[1])
+	assert.Equal(t, customerDomainEntity.CustomerHighlight{Field: "full_name", Match: MatchPrefix, Snippet: "Nur <em>Ahsan</em>ul"}, customers[1].Highlights[0])
+	assert.Greater(t, customers[0].Score, customers[1].Score)
+}
+
+func TestRankSearchResultsMatchesTypos(t *testing.T) {
+	customers := rankSearchResults("sandia ahsn", searchCandidates())
+
+	assert.Len(t, customers, 1)
+	assert.Equal(t, int64(2), customers[0].ID)
+	assert.Equal(t, MatchFuzzy, customers[0].Highlights[0].Match)
+	assert.Equal(t, "<em>Ahsan</em> <em>Sandia</em>h", customers[0].Highlights[0].Snippet)
+}
+
+func TestRankSearchResultsMatchesLocalPhoneNumbers(t *testing.T) {
+	customers := rankSearchResults("0812-3456", searchCandidates())
+
+	assert.Len(t, customers, 1)
+	assert.Equal(t, customerDomainEntity.CustomerHighlight{Field: "phone_number", Match: MatchPrefix, Snippet: "+62<em>8123456</em>7890"}, customers[0].Highlights[0])
+}
+
+func TestRankSearchResultsDropsUnmatchedCandidates(t *testing.T) {
+	assert.Empty(t, rankSearchResults("zzz", searchCandidates()))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("ahsan", "ahsan"))
+	assert.Equal(t, 1, levenshtein("ahsan", "ahsn"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+}
+
+func TestRankSearchResultsEscapesHighlights(t *testing.T) {
+	candidates := []customerDomainEntity.Customer{{ID: 4, FullName: `Ahsan <img src=x onerror="alert(1)">`, Email: "a&b@example.com"}}
+
+	customers := rankSearchResults("Ahsan", candidates)
+
+	assert.Len(t, customers, 1)
+	assert.Equal(t, "<em>Ahsan</em> &lt;img src=x onerror=&#34;alert(1)&#34;&gt;", customers[0].Highlights[0].Snippet)
+}
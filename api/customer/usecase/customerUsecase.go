@@ -3,19 +3,36 @@ package customerUsecase
 import (
 	"context"
 	"errors"
+	"time"
 
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	auditUsecase "github.com/ahsansandiah/dpo-test/api/audit/usecase"
 	customerDomainInterface "github.com/ahsansandiah/dpo-test/api/customer/domain"
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 	customerRepository "github.com/ahsansandiah/dpo-test/api/customer/repository"
+	orderDomainInterface "github.com/ahsansandiah/dpo-test/api/order/domain"
+	orderUsecase "github.com/ahsansandiah/dpo-test/api/order/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
 )
 
 type CustomerUsecase struct {
-	log  log.Log
-	cfg  *config.Config
-	repo customerDomainInterface.CustomerRepository
+	log    log.Log
+	cfg    *config.Config
+	repo   customerDomainInterface.CustomerRepository
+	trx    transactionDatabase.Transaction
+	audit  auditDomainInterface.AuditUsecase
+	outbox outbox.Outbox
+	orders orderDomainInterface.OrderUsecase
 }
 
 func NewCustomerUsecase(mgr manager.Manager) customerDomainInterface.CustomerUsecase {
@@ -23,90 +40,303 @@ func NewCustomerUsecase(mgr manager.Manager) customerDomainInterface.CustomerUse
 	usecase.log = mgr.GetLog()
 	usecase.cfg = mgr.GetConfig()
 	usecase.repo = customerRepository.NewCustomerRepository(mgr)
+	usecase.trx = mgr.GetTransaction()
+	usecase.audit = auditUsecase.NewAuditUsecase(mgr)
+	usecase.outbox = mgr.GetOutbox()
+	usecase.orders = orderUsecase.NewOrderUsecase(mgr)
 
 	return usecase
 }
 
-func (u *CustomerUsecase) GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) ([]customerDomainEntity.Customer, error) {
+func (u *CustomerUsecase) GetAll(ctx context.Context, filter *customerDomainEntity.CustomerFilter) (*customerDomainEntity.CustomerListResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if filter.Deleted != softDeleteHelper.ScopeActive {
+		principal, err := principalAuth.Authenticated(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !principal.HasPermission(customerDomainEntity.PermissionCustomerRestore) {
+			return nil, errorHelper.ErrorDeletedForbidden
+		}
+	}
+
+	if filter.Q != "" {
+		return u.search(ctx, filter)
+	}
+
+	sortBy, err := paginateHelper.ValidateSortBy(filter.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	filter.SortBy = sortBy
+	filter.LIMIT = paginateHelper.ValidateLimit(filter.LIMIT)
+
+	if filter.Cursor != "" {
+		cursor, err := paginateHelper.ParseCursor(filter.Cursor, u.cfg.PaginateCursorSecret)
+		if err != nil {
+			return nil, errorHelper.Wrap(err, "Error parsing cursor")
+		}
+		filter.Keyset = cursor
+		filter.SortBy = cursor.SortBy
+	}
+
+	// fetch one extra customer to know whether another page exists
+	limit := filter.LIMIT
+	filter.LIMIT = limit + 1
 	customers, err := u.repo.GetAll(ctx, filter)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching customers")
+	}
+	filter.LIMIT = limit
+
+	hasMore := len(customers) > limit
+	if hasMore {
+		customers = customers[:limit]
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		for i, j := 0, len(customers)-1; i < j; i, j = i+1, j-1 {
+			customers[i], customers[j] = customers[j], customers[i]
+		}
+	}
+
+	var first, last *paginateHelper.Cursor
+	if len(customers) > 0 {
+		firstCursor := paginateHelper.NewCursor(filter.SortBy, customers[0].CreatedAt, customers[0].ID)
+		lastCursor := paginateHelper.NewCursor(filter.SortBy, customers[len(customers)-1].CreatedAt, customers[len(customers)-1].ID)
+		first, last = &firstCursor, &lastCursor
+	}
+
+	paginate, err := paginateHelper.NewPaginate(u.cfg.PaginateCursorSecret, filter.Keyset, hasMore, first, last)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error paginating customers")
+	}
+
+	result := &customerDomainEntity.CustomerListResponse{
+		Customer: customers,
+		Paginate: paginate,
+	}
+
+	return result, nil
+}
+
+// search returns the customers best matching filter.Q. Results are ranked by
+// relevance, which keyset cursors can not follow, so a search has one page of
+// up to filter.LIMIT customers.
+func (u *CustomerUsecase) search(ctx context.Context, filter *customerDomainEntity.CustomerFilter) (*customerDomainEntity.CustomerListResponse, error) {
+	if err := filter.Validate(); err != nil {
 		return nil, err
 	}
+	limit := paginateHelper.ValidateLimit(filter.LIMIT)
 
-	return customers, nil
+	filter.LIMIT = searchCandidateLimit
+	candidates, err := u.repo.Search(ctx, filter)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error searching customers")
+	}
+	filter.LIMIT = limit
+
+	customers := rankSearchResults(filter.Q, candidates)
+	if len(customers) > limit {
+		customers = customers[:limit]
+	}
+
+	result := &customerDomainEntity.CustomerListResponse{
+		Customer: customers,
+		Paginate: &paginateHelper.Paginate{},
+	}
+
+	return result, nil
 }
 
+// Delete soft deletes a customer together with its orders that have not
+// shipped yet, Restore brings both back.
 func (u *CustomerUsecase) Delete(ctx context.Context, ID int64) error {
-	_, err := u.repo.GetById(ctx, ID)
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		customer, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		deletedAt := time.Now()
+		if err := u.repo.Delete(ctx, ID, deletedAt); err != nil {
+			return err
+		}
+
+		// the orders deleted with the customer share its deleted_at and give
+		// their stock back
+		if err := u.orders.DeleteOfCustomer(ctx, ID, deletedAt); err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityCustomer, ID, auditDomainEntity.ActionDelete, customer, nil); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, customerDomainEntity.EventAggregateCustomer, ID, customerDomainEntity.EventCustomerDeleted, customer)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching customer details")
-		return errMsg
+		return errorHelper.Wrap(err, "Error deleting customer")
 	}
 
-	err = u.repo.Delete(ctx, ID)
+	return nil
+}
+
+// Restore brings back a deleted customer and the orders deleted with it.
+func (u *CustomerUsecase) Restore(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		customer, err := u.repo.GetDeletedByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.repo.Restore(ctx, ID); err != nil {
+			return err
+		}
+
+		if err := u.orders.RestoreOfCustomer(ctx, ID, *customer.DeletedAt); err != nil {
+			return err
+		}
+
+		restored, err := u.repo.GetById(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityCustomer, ID, auditDomainEntity.ActionRestore, customer, restored); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, customerDomainEntity.EventAggregateCustomer, ID, customerDomainEntity.EventCustomerRestored, restored)
+	})
+	if errors.Is(err, errorHelper.ErrorInsufficientStock) {
+		return nil, err
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error deleting customer")
-		return errMsg
+		return nil, errorHelper.Wrap(err, "Error restoring customer")
 	}
 
-	return nil
+	return u.GetByID(ctx, ID)
 }
 
 func (u *CustomerUsecase) GetByID(ctx context.Context, ID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	customer, err := u.repo.GetById(ctx, ID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching customer details")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error fetching customer details")
 	}
 
 	return customer, nil
 }
 
 func (u *CustomerUsecase) Update(ctx context.Context, ID int64, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error) {
-	customer, err := u.repo.GetById(ctx, ID)
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	var result *customerDomainEntity.Customer
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		customer, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		result, err = u.repo.Update(ctx, ID, request)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityCustomer, ID, auditDomainEntity.ActionUpdate, customer, result); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, customerDomainEntity.EventAggregateCustomer, ID, customerDomainEntity.EventCustomerUpdated, result)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching customer details")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error updating customer")
 	}
 
-	if request.FullName == "" {
-		request.FullName = customer.FullName
-	}
+	return result, nil
+}
 
-	if request.Address == "" {
-		request.Address = customer.Address
-	}
+func (u *CustomerUsecase) Create(ctx context.Context, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
 
-	if request.PhoneNumber == "" {
-		request.PhoneNumber = customer.PhoneNumber
-	}
+	var customer *customerDomainEntity.Customer
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		customer, err = u.repo.Create(ctx, request)
+		if err != nil {
+			return err
+		}
 
-	if request.Email == "" {
-		request.Email = customer.Email
-	}
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityCustomer, customer.ID, auditDomainEntity.ActionCreate, nil, customer); err != nil {
+			return err
+		}
 
-	result, err := u.repo.Update(ctx, ID, request)
+		return u.outbox.Add(ctx, customerDomainEntity.EventAggregateCustomer, customer.ID, customerDomainEntity.EventCustomerCreated, customer)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error updating customer")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error inserting customer")
 	}
 
-	return result, nil
+	return customer, nil
 }
 
-func (u *CustomerUsecase) Create(ctx context.Context, request *customerDomainEntity.CustomerRequest) (*customerDomainEntity.Customer, error) {
-	custmer, err := u.repo.Create(ctx, request)
+// SetActive activates or deactivates a customer, inactive customers can not
+// place orders. Setting the state the customer already has is a no-op.
+func (u *CustomerUsecase) SetActive(ctx context.Context, ID int64, active bool) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		customer, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if customer.IsActive == active {
+			return nil
+		}
+
+		if err := u.repo.SetActive(ctx, ID, active); err != nil {
+			return err
+		}
+
+		updated, err := u.repo.GetById(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityCustomer, ID, auditDomainEntity.ActionUpdate, customer, updated); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, customerDomainEntity.EventAggregateCustomer, ID, customerDomainEntity.EventCustomerUpdated, updated)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error inserting customer")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error updating customer")
 	}
 
-	return custmer, nil
+	return u.GetByID(ctx, ID)
 }
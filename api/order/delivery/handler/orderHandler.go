@@ -9,6 +9,10 @@ import (
 	orderDomainInterface "github.com/ahsansandiah/dpo-test/api/order/domain"
 	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
 	orderUsecase "github.com/ahsansandiah/dpo-test/api/order/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	res "github.com/ahsansandiah/dpo-test/packages/json"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
@@ -31,11 +35,13 @@ func NewOrderHandler(mgr manager.Manager) orderDomainInterface.OrderHandler {
 
 func (h *Order) GetAll() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 		queryParams := r.URL.Query()
 
 		limitStr := queryParams.Get("limit")
-		limit := 10
+		limit := paginateHelper.DefaultLimit
 		if limitStr != "" {
 			l, err := strconv.Atoi(limitStr)
 			if err == nil {
@@ -43,113 +49,244 @@ func (h *Order) GetAll() http.Handler {
 			}
 		}
 
+		deleted, err := softDeleteHelper.ParseScope(queryParams.Get("include_deleted"), queryParams.Get("only_deleted"))
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
 		filter := &orderDomainEntity.OrderFilter{
 			OrderDate:  queryParams.Get("order_date"),
 			CustomerID: queryParams.Get("customer_id"),
 			Status:     queryParams.Get("status"),
 			LIMIT:      limit,
+			Cursor:     queryParams.Get("cursor"),
+			SortBy:     queryParams.Get("sort_by"),
+			Deleted:    deleted,
 		}
 
 		result, err := h.Usecase.GetAll(ctx, filter)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success get data", result)
+		h.Json.PaginateResponse(w, r, "Success get data", result.Order, result.Paginate)
 	})
 }
 
 func (h *Order) Delete() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		orderIDStr := mux.Vars(r)["id"]
 		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid order ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		err = h.Usecase.Delete(ctx, orderID)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, fmt.Sprintf("Order with ID %d deleted successfully", orderID), nil)
+		h.Json.SuccessResponse(w, r, fmt.Sprintf("Order with ID %d deleted successfully", orderID), nil)
 	})
 }
 
 func (h *Order) GetByID() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		orderIDStr := mux.Vars(r)["id"]
 		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid order ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		order, err := h.Usecase.GetByID(ctx, orderID)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success get data", order)
+		h.Json.SuccessResponse(w, r, "Success get data", order)
 	})
 }
 
 func (h *Order) Update() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		orderIDStr := mux.Vars(r)["id"]
 		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid order ID", http.StatusBadRequest)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
 			return
 		}
 
 		var req *orderDomainEntity.OrderUpdateRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
 			return
 		}
 
 		order, err := h.Usecase.Update(ctx, orderID, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success updated", order)
+		h.Json.SuccessResponse(w, r, "Success updated", order)
 	})
 }
 
 func (h *Order) Create() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		var req *orderDomainEntity.OrderRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
 		order, err := h.Usecase.Create(ctx, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.CreatedResponse(w, r, "Success created", order)
+	})
+}
+
+func (h *Order) UpdateItems() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		orderIDStr := mux.Vars(r)["id"]
+		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		var req *orderDomainEntity.OrderItemsPatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		order, err := h.Usecase.UpdateItems(ctx, orderID, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success updated items", order)
+	})
+}
+
+func (h *Order) Transition() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		orderIDStr := mux.Vars(r)["id"]
+		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		var req *orderDomainEntity.OrderTransitionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		order, err := h.Usecase.Transition(ctx, orderID, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success updated status", order)
+	})
+}
+
+func (h *Order) History() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		orderIDStr := mux.Vars(r)["id"]
+		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		histories, err := h.Usecase.GetStatusHistory(ctx, orderID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success get data", histories)
+	})
+}
+
+func (h *Order) Restore() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		orderIDStr := mux.Vars(r)["id"]
+		orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		order, err := h.Usecase.Restore(ctx, orderID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success created", order)
+		h.Json.SuccessResponse(w, r, "Success restored", order)
 	})
 }
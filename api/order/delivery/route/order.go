@@ -2,16 +2,22 @@ package orderRoute
 
 import (
 	orderHandler "github.com/ahsansandiah/dpo-test/api/order/delivery/handler"
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
 	"github.com/gorilla/mux"
 )
 
 func NewOrderRoute(mgr manager.Manager, route *mux.Router) {
 	orderHandler := orderHandler.NewOrderHandler(mgr)
+	can := mgr.GetMiddleware().RequirePermission
 
-	route.Handle("/orders", orderHandler.GetAll()).Methods("GET")
-	route.Handle("/orders/{id}", orderHandler.Delete()).Methods("DELETE")
-	route.Handle("/orders/{id}", orderHandler.GetByID()).Methods("GET")
-	route.Handle("/orders/{id}", orderHandler.Update()).Methods("PUT")
-	route.Handle("/orders", orderHandler.Create()).Methods("POST")
+	route.Handle("/orders", can(orderDomainEntity.PermissionOrderRead)(orderHandler.GetAll())).Methods("GET")
+	route.Handle("/orders/{id}", can(orderDomainEntity.PermissionOrderDelete)(orderHandler.Delete())).Methods("DELETE")
+	route.Handle("/orders/{id}", can(orderDomainEntity.PermissionOrderRead)(orderHandler.GetByID())).Methods("GET")
+	route.Handle("/orders/{id}", can(orderDomainEntity.PermissionOrderWrite)(orderHandler.Update())).Methods("PUT")
+	route.Handle("/orders", can(orderDomainEntity.PermissionOrderWrite)(orderHandler.Create())).Methods("POST")
+	route.Handle("/orders/{id}/items", can(orderDomainEntity.PermissionOrderWrite)(orderHandler.UpdateItems())).Methods("PATCH")
+	route.Handle("/orders/{id}/transitions", can(orderDomainEntity.PermissionOrderTransition)(orderHandler.Transition())).Methods("POST")
+	route.Handle("/orders/{id}/history", can(orderDomainEntity.PermissionOrderRead)(orderHandler.History())).Methods("GET")
+	route.Handle("/orders/{id}/restore", can(orderDomainEntity.PermissionOrderRestore)(orderHandler.Restore())).Methods("POST")
 }
@@ -1,75 +1,114 @@
 package orderDomainEntity
 
 import (
+	"fmt"
 	"time"
 
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
-	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
 	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
 )
 
+const (
+	PermissionOrderRead       = "orders:read"
+	PermissionOrderWrite      = "orders:write"
+	PermissionOrderDelete     = "orders:delete"
+	PermissionOrderTransition = "orders:transition"
+	PermissionOrderRestore    = "orders:restore"
+)
+
+// Domain events of orders, published through the outbox. The payload is the
+// order, OrderStatusChanged carries an OrderStatusChangedEvent.
+const (
+	EventAggregateOrder = "order"
+
+	EventOrderCreated       = "OrderCreated"
+	EventOrderUpdated       = "OrderUpdated"
+	EventOrderStatusChanged = "OrderStatusChanged"
+	EventOrderDeleted       = "OrderDeleted"
+	EventOrderRestored      = "OrderRestored"
+)
+
+// OrderStatusChangedEvent is the payload of OrderStatusChanged, cancelling an
+// order is a change to OrderStatusCancelled.
+type OrderStatusChangedEvent struct {
+	FromStatus string         `json:"from_status"`
+	ToStatus   string         `json:"to_status"`
+	Reason     string         `json:"reason"`
+	ChangedBy  int64          `json:"changed_by"`
+	Order      *OrderResponse `json:"order"`
+}
+
 type Order struct {
-	ID          int64     `json:"id"`
-	CustomerID  int64     `json:"customer_id"`
-	OrderDate   time.Time `json:"order_date"`
-	Status      string    `json:"status"`
-	TotalAmount float64   `json:"total_amount"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             int64           `json:"id"`
+	CustomerID     int64           `json:"customer_id"`
+	OrderDate      time.Time       `json:"order_date"`
+	Status         string          `json:"status"`
+	Subtotal       decimal.Decimal `json:"subtotal"`
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+	TaxAmount      decimal.Decimal `json:"tax_amount"`
+	TotalAmount    decimal.Decimal `json:"total_amount"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      *time.Time      `json:"deleted_at,omitempty"`
 }
 
 type OrderRequest struct {
-	CustomerID  int64              `json:"customer_id"`
-	OrderDate   time.Time          `json:"order_date"`
-	TotalAmount float64            `json:"total_amount"`
+	CustomerID int64     `json:"customer_id"`
+	OrderDate  time.Time `json:"order_date"`
+	// TotalAmount is optional, when sent it must match the computed grand total
+	TotalAmount *decimal.Decimal   `json:"total_amount"`
 	OrderItems  []OrderItemRequest `json:"order_items"`
 }
 
 type OrderUpdateRequest struct {
-	OrderDate   time.Time `json:"order_date"`
-	TotalAmount float64   `json:"total_amount"`
+	OrderDate time.Time `json:"order_date"`
 }
 
 type OrderResponse struct {
-	ID          int64                          `json:"id"`
-	OrderDate   time.Time                      `json:"order_date"`
-	TotalAmount float64                        `json:"total_amount"`
-	Status      string                         `json:"status"`
-	Customer    *customerDomainEntity.Customer `json:"customer"`
-	Items       []OrderItem                    `json:"items"`
-	CreatedAt   time.Time                      `json:"created_at"`
-	UpdatedAt   time.Time                      `json:"updated_at"`
+	ID             int64                          `json:"id"`
+	OrderDate      time.Time                      `json:"order_date"`
+	Subtotal       decimal.Decimal                `json:"subtotal"`
+	DiscountAmount decimal.Decimal                `json:"discount_amount"`
+	TaxAmount      decimal.Decimal                `json:"tax_amount"`
+	TotalAmount    decimal.Decimal                `json:"total_amount"`
+	Status         string                         `json:"status"`
+	Customer       *customerDomainEntity.Customer `json:"customer"`
+	Items          []OrderItem                    `json:"items"`
+	CreatedAt      time.Time                      `json:"created_at"`
+	UpdatedAt      time.Time                      `json:"updated_at"`
+	// DeletedAt is only set on deleted orders, which only admins can list
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type OrderListRespone struct {
-	Order  []OrderResponse
-	Cursor *paginateHelper.Cursor `json:"Cursor"`
+	Order    []OrderResponse          `json:"order"`
+	Paginate *paginateHelper.Paginate `json:"paginate"`
 }
 
 type OrderFilter struct {
-	CustomerID string `json:"customer_id"`
-	OrderDate  string `json:"order_date"`
-	Status     string `json:"status"`
-	LIMIT      int    `json:"limit"`
-	Cursor     string `json:"cursor"`
+	CustomerID string                 `json:"customer_id"`
+	OrderDate  string                 `json:"order_date"`
+	Status     string                 `json:"status"`
+	LIMIT      int                    `json:"limit"`
+	Cursor     string                 `json:"cursor"`
+	SortBy     string                 `json:"sort_by"`
+	Keyset     *paginateHelper.Cursor `json:"-"`
+	Deleted    softDeleteHelper.Scope `json:"-"`
 }
 
 func (r *OrderRequest) Validate() error {
-	if r.CustomerID < 0 {
-		return errorHelper.ErrorFullNameIsRequired
-	}
-
-	if r.OrderDate.IsZero() {
-		return errorHelper.ErrorOrderDateRequired
-	}
-
-	if r.TotalAmount < 0 {
-		return errorHelper.ErrorAmountIsRequired
-	}
+	v := validationHelper.New()
+	v.Min("customer_id", r.CustomerID, 1)
+	v.Check(!r.OrderDate.IsZero(), "order_date", validationHelper.RuleRequired, "order_date is required")
 
-	if len(r.OrderItems) == 0 {
-		return errorHelper.ErrorOrderItemsIsRequired
+	if v.Check(len(r.OrderItems) > 0, "order_items", validationHelper.RuleRequired, "order_items is required") {
+		for i := range r.OrderItems {
+			r.OrderItems[i].validate(v, fmt.Sprintf("order_items[%d]", i))
+		}
 	}
 
-	return nil
+	return v.Err()
 }
@@ -1,21 +1,83 @@
 package orderDomainEntity
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+)
 
 type OrderItem struct {
-	ID          int64     `json:"id"`
-	OrderID     int64     `json:"order_id"`
-	ProductName string    `json:"product_name"`
-	Quantity    int       `json:"quantity"`
-	Price       int       `json:"price"`
-	TotalPrice  int       `json:"total_price"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64           `json:"id"`
+	OrderID     int64           `json:"order_id"`
+	ProductID   int64           `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+	Discount    decimal.Decimal `json:"discount"`
+	TotalPrice  decimal.Decimal `json:"total_price"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
 type OrderItemRequest struct {
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	TotalPrice  float64 `json:"total_price"`
+	ProductID int64           `json:"product_id"`
+	Quantity  int             `json:"quantity"`
+	Discount  decimal.Decimal `json:"discount"`
+	// TotalPrice is optional, when sent it must match the computed line total
+	TotalPrice *decimal.Decimal `json:"total_price"`
+}
+
+func (r *OrderItemRequest) validate(v *validationHelper.Validator, prefix string) {
+	v.Min(prefix+".product_id", r.ProductID, 1)
+	v.Min(prefix+".quantity", int64(r.Quantity), 1)
+	v.Check(!r.Discount.IsNegative(), prefix+".discount", validationHelper.RuleMin, prefix+".discount can not be negative")
+}
+
+type OrderItemUpdateRequest struct {
+	ID       int64            `json:"id"`
+	Quantity int              `json:"quantity"`
+	Discount *decimal.Decimal `json:"discount"`
+}
+
+// OrderItemsPatchRequest adds, updates and removes line items of an order.
+type OrderItemsPatchRequest struct {
+	Add    []OrderItemRequest       `json:"add"`
+	Update []OrderItemUpdateRequest `json:"update"`
+	Remove []int64                  `json:"remove"`
+}
+
+// OrderItemChanges is the set of line item writes, plus the stock every
+// product has to reserve (positive) or release (negative), of one patch.
+type OrderItemChanges struct {
+	Added       []OrderItem
+	Updated     []OrderItem
+	Removed     []OrderItem
+	StockDeltas map[int64]int
+}
+
+func (r *OrderItemsPatchRequest) Validate() error {
+	v := validationHelper.New()
+	if !v.Check(len(r.Add) > 0 || len(r.Update) > 0 || len(r.Remove) > 0, "items", validationHelper.RuleRequired, "at least one item to add, update or remove is required") {
+		return v.Err()
+	}
+
+	for i := range r.Add {
+		r.Add[i].validate(v, fmt.Sprintf("add[%d]", i))
+	}
+
+	for i, item := range r.Update {
+		v.Min(fmt.Sprintf("update[%d].id", i), item.ID, 1)
+		v.Min(fmt.Sprintf("update[%d].quantity", i), int64(item.Quantity), 1)
+		if item.Discount != nil {
+			v.Check(!item.Discount.IsNegative(), fmt.Sprintf("update[%d].discount", i), validationHelper.RuleMin, fmt.Sprintf("update[%d].discount can not be negative", i))
+		}
+	}
+
+	for i, ID := range r.Remove {
+		v.Min(fmt.Sprintf("remove[%d]", i), ID, 1)
+	}
+
+	return v.Err()
 }
@@ -0,0 +1,40 @@
+package orderDomainEntity
+
+import (
+	"time"
+
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+)
+
+const (
+	OrderStatusPending    = "Pending"
+	OrderStatusConfirmed  = "Confirmed"
+	OrderStatusProcessing = "Processing"
+	OrderStatusShipped    = "Shipped"
+	OrderStatusDelivered  = "Delivered"
+	OrderStatusCancelled  = "Cancelled"
+	OrderStatusReturned   = "Returned"
+)
+
+type OrderStatusHistory struct {
+	ID         int64     `json:"id"`
+	OrderID    int64     `json:"order_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  int64     `json:"changed_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrderTransitionRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (r *OrderTransitionRequest) Validate() error {
+	v := validationHelper.New()
+	v.Required("status", r.Status)
+	v.Length("reason", r.Reason, 1, 255)
+
+	return v.Err()
+}
@@ -3,6 +3,7 @@ package orderDomainInterface
 import (
 	"context"
 	"net/http"
+	"time"
 
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
@@ -14,23 +15,51 @@ type OrderHandler interface {
 	GetByID() http.Handler
 	Update() http.Handler
 	Create() http.Handler
+	UpdateItems() http.Handler
+	Transition() http.Handler
+	History() http.Handler
+	Restore() http.Handler
 }
 
 type OrderUsecase interface {
-	GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) ([]orderDomainEntity.OrderResponse, error)
+	GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) (*orderDomainEntity.OrderListRespone, error)
 	Delete(ctx context.Context, ID int64) error
 	GetByID(ctx context.Context, ID int64) (*orderDomainEntity.OrderResponse, error)
 	Update(ctx context.Context, ID int64, request *orderDomainEntity.OrderUpdateRequest) (*orderDomainEntity.OrderResponse, error)
-	Create(ctx context.Context, request *orderDomainEntity.OrderRequest) (*orderDomainEntity.OrderRequest, error)
-	ValidateCustomer(ctx context.Context, customerID int64) bool
+	Create(ctx context.Context, request *orderDomainEntity.OrderRequest) (*orderDomainEntity.OrderResponse, error)
+	UpdateItems(ctx context.Context, ID int64, request *orderDomainEntity.OrderItemsPatchRequest) (*orderDomainEntity.OrderResponse, error)
+	ValidateCustomer(ctx context.Context, customerID int64) error
+	Transition(ctx context.Context, ID int64, request *orderDomainEntity.OrderTransitionRequest) (*orderDomainEntity.OrderResponse, error)
+	GetStatusHistory(ctx context.Context, ID int64) ([]orderDomainEntity.OrderStatusHistory, error)
+	Restore(ctx context.Context, ID int64) (*orderDomainEntity.OrderResponse, error)
+	// DeleteOfCustomer deletes the orders of a customer being deleted that
+	// have not shipped yet, with its deleted_at, inside the transaction of ctx.
+	DeleteOfCustomer(ctx context.Context, customerID int64, deletedAt time.Time) error
+	// RestoreOfCustomer restores the orders deleted with a customer being
+	// restored inside the transaction of ctx.
+	RestoreOfCustomer(ctx context.Context, customerID int64, deletedAt time.Time) error
 }
 
 type OrderRepository interface {
 	GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) ([]orderDomainEntity.OrderResponse, error)
 	GetById(ctx context.Context, ID int64) (*orderDomainEntity.Order, error)
-	Delete(ctx context.Context, ID int64) error
+	GetByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error)
+	GetDeletedByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error)
+	Delete(ctx context.Context, ID int64, deletedAt time.Time) error
+	Restore(ctx context.Context, ID int64) error
+	GetOpenByCustomerForUpdate(ctx context.Context, customerID int64) ([]orderDomainEntity.Order, error)
+	GetDeletedByCustomerForUpdate(ctx context.Context, customerID int64, deletedAt time.Time) ([]orderDomainEntity.Order, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
 	Update(ctx context.Context, ID int64, request *orderDomainEntity.OrderUpdateRequest) (*orderDomainEntity.Order, error)
-	Create(ctx context.Context, request *orderDomainEntity.OrderRequest) error
+	Create(ctx context.Context, order *orderDomainEntity.Order) (int64, error)
+	UpdateTotals(ctx context.Context, order *orderDomainEntity.Order) error
 	GetCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error)
+	GetOrderCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error)
 	GetOrderItems(ctx context.Context, orderId int64) ([]orderDomainEntity.OrderItem, error)
+	CreateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error
+	UpdateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error
+	DeleteItem(ctx context.Context, orderID int64, itemID int64) error
+	UpdateStatus(ctx context.Context, ID int64, fromStatus string, toStatus string) error
+	CreateStatusHistory(ctx context.Context, history *orderDomainEntity.OrderStatusHistory) error
+	GetStatusHistory(ctx context.Context, orderID int64) ([]orderDomainEntity.OrderStatusHistory, error)
 }
@@ -8,20 +8,24 @@ import (
 	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
 	orderDomainInterface "github.com/ahsansandiah/dpo-test/api/order/domain"
 	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
 )
 
 type Order struct {
-	DB  *sql.DB
+	DB  transactionDatabase.Transaction
 	log log.Log
 	cfg *config.Config
 }
 
 func NewOrderRepository(mgr manager.Manager) orderDomainInterface.OrderRepository {
 	repo := new(Order)
-	repo.DB = mgr.GetDB()
+	repo.DB = mgr.GetTransaction()
 	repo.log = mgr.GetLog()
 	repo.cfg = mgr.GetConfig()
 
@@ -29,45 +33,60 @@ func NewOrderRepository(mgr manager.Manager) orderDomainInterface.OrderRepositor
 }
 
 func (r *Order) GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) ([]orderDomainEntity.OrderResponse, error) {
-	query := `SELECT 
-                o.id, o.customer_id, o.order_date, o.status, o.total_amount, o.created_at, o.updated_at,
-                c.id, c.full_name, c.address, c.phone_number, c.email, c.is_active, c.created_at, c.updated_at,
-                oi.id, oi.order_id, oi.product_name, oi.quantity, oi.price, oi.total_price, oi.created_at, oi.updated_at
-              FROM orders o
-              INNER JOIN customers c ON o.customer_id = c.id
-              LEFT JOIN order_items oi ON o.id = oi.order_id
-              WHERE o.deleted_at IS NULL`
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	// Page the orders first and join their items afterwards, so the limit
+	// applies to orders rather than to joined item rows.
+	pageQuery := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at FROM orders o WHERE TRUE"
+	pageQuery += filter.Deleted.Condition("o")
 
 	var args []interface{}
 
 	// Apply filters
-	if filter != nil {
-		if filter.CustomerID != "" {
-			query += " AND o.customer_id = ?"
-			args = append(args, filter.CustomerID)
-		}
-		if filter.OrderDate != "" {
-			query += " AND o.order_date = ?"
-			args = append(args, filter.OrderDate)
-		}
-		if filter.Status != "" {
-			query += " AND o.status = ?"
-			args = append(args, filter.Status)
-		}
+	if filter.CustomerID != "" {
+		pageQuery += " AND o.customer_id = ?"
+		args = append(args, filter.CustomerID)
+	}
+	if filter.OrderDate != "" {
+		pageQuery += " AND o.order_date = ?"
+		args = append(args, filter.OrderDate)
+	}
+	if filter.Status != "" {
+		pageQuery += " AND o.status = ?"
+		args = append(args, filter.Status)
 	}
 
 	// Add pagination
-	query += " LIMIT ?"
+	backward := false
+	if filter.Keyset != nil {
+		condition, keysetArgs := filter.Keyset.Condition("o")
+		pageQuery += condition
+		args = append(args, keysetArgs...)
+		backward = filter.Keyset.Backward
+	}
+	pageQuery += paginateHelper.OrderBy("o", filter.SortBy, backward)
+	pageQuery += " LIMIT ?"
 	args = append(args, filter.LIMIT)
 
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+	query := `SELECT 
+                o.id, o.customer_id, o.order_date, o.status, o.subtotal, o.discount_amount, o.tax_amount, o.total_amount, o.created_at, o.updated_at, o.deleted_at,
+                c.id, c.full_name, c.address, c.phone_number, c.email, c.is_active, c.created_at, c.updated_at, c.deleted_at,
+                COALESCE(oi.id, 0), COALESCE(oi.order_id, 0), COALESCE(oi.product_id, 0), COALESCE(oi.product_name, ''), COALESCE(oi.quantity, 0), COALESCE(oi.price, 0), COALESCE(oi.discount, 0), COALESCE(oi.total_price, 0), COALESCE(oi.created_at, o.created_at), COALESCE(oi.updated_at, o.updated_at)
+              FROM (` + pageQuery + `) o
+              INNER JOIN customers c ON o.customer_id = c.id
+              LEFT JOIN order_items oi ON o.id = oi.order_id` +
+		paginateHelper.OrderBy("o", filter.SortBy, backward) + ", oi.id"
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	orderMap := make(map[int64]*orderDomainEntity.OrderResponse)
+	orders := []orderDomainEntity.OrderResponse{}
+	orderIndex := make(map[int64]int)
 	for rows.Next() {
 		var order orderDomainEntity.OrderResponse
 		var customer customerDomainEntity.Customer
@@ -77,22 +96,24 @@ func (r *Order) GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilte
 		order.Customer = &customer
 
 		err := rows.Scan(
-			&order.ID, &order.Customer.ID, &order.OrderDate, &order.Status, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt,
-			&order.Customer.ID, &order.Customer.FullName, &order.Customer.Address, &order.Customer.PhoneNumber, &order.Customer.Email, &order.Customer.IsActive, &order.Customer.CreatedAt, &order.Customer.UpdatedAt,
-			&item.ID, &item.OrderID, &item.ProductName, &item.Quantity, &item.Price, &item.TotalPrice, &item.CreatedAt, &item.UpdatedAt,
+			&order.ID, &order.Customer.ID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt, &order.DeletedAt,
+			&order.Customer.ID, &order.Customer.FullName, &order.Customer.Address, &order.Customer.PhoneNumber, &order.Customer.Email, &order.Customer.IsActive, &order.Customer.CreatedAt, &order.Customer.UpdatedAt, &order.Customer.DeletedAt,
+			&item.ID, &item.OrderID, &item.ProductID, &item.ProductName, &item.Quantity, &item.Price, &item.Discount, &item.TotalPrice, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
 			r.log.ErrorLog(ctx, err)
 			return nil, err
 		}
 
-		if existingOrder, exists := orderMap[order.ID]; exists {
-			existingOrder.Items = append(existingOrder.Items, item)
-		} else {
-			if item.ID != 0 {
-				order.Items = append(order.Items, item)
-			}
-			orderMap[order.ID] = &order
+		index, exists := orderIndex[order.ID]
+		if !exists {
+			orders = append(orders, order)
+			index = len(orders) - 1
+			orderIndex[order.ID] = index
+		}
+
+		if item.ID != 0 {
+			orders[index].Items = append(orders[index].Items, item)
 		}
 	}
 	if err := rows.Err(); err != nil {
@@ -100,19 +121,20 @@ func (r *Order) GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilte
 		return nil, err
 	}
 
-	orders := make([]orderDomainEntity.OrderResponse, 0, len(orderMap))
-	for _, order := range orderMap {
-		orders = append(orders, *order)
-	}
-
 	return orders, nil
 }
 
 func (r *Order) GetById(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	order := orderDomainEntity.Order{}
 
-	query := "SELECT id, customer_id, order_date, status, total_amount, created_at, updated_at FROM orders WHERE id = ?"
-	err := r.DB.QueryRow(query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at FROM orders WHERE id = ? AND deleted_at IS NULL"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorOrderNotFound
+	}
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -121,16 +143,18 @@ func (r *Order) GetById(ctx context.Context, ID int64) (*orderDomainEntity.Order
 	return &order, nil
 }
 
-func (r *Order) Delete(ctx context.Context, ID int64) error {
-	stmt, err := r.DB.Prepare("UPDATE orders SET deleted_at = ? WHERE id = ?")
+func (r *Order) Delete(ctx context.Context, ID int64, deletedAt time.Time) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	stmt, err := r.DB.Executor(ctx).PrepareContext(ctx, "UPDATE orders SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
 	}
 	defer stmt.Close()
 
-	// Set deleted_at to current timestamp
-	_, err = stmt.Exec(time.Now(), ID)
+	_, err = stmt.ExecContext(ctx, deletedAt, ID)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
@@ -140,8 +164,11 @@ func (r *Order) Delete(ctx context.Context, ID int64) error {
 }
 
 func (r *Order) Update(ctx context.Context, ID int64, request *orderDomainEntity.OrderUpdateRequest) (*orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	var order orderDomainEntity.Order
-	stmt, err := r.DB.PrepareContext(ctx, "UPDATE orders SET order_date = ?, total_amount = ? WHERE id = ?")
+	stmt, err := r.DB.Executor(ctx).PrepareContext(ctx, "UPDATE orders SET order_date = ? WHERE id = ? AND deleted_at IS NULL")
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -149,15 +176,15 @@ func (r *Order) Update(ctx context.Context, ID int64, request *orderDomainEntity
 	defer stmt.Close()
 
 	// Execute UPDATE statement
-	_, err = stmt.ExecContext(ctx, request.OrderDate, request.TotalAmount, ID)
+	_, err = stmt.ExecContext(ctx, request.OrderDate, ID)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 
 	// Query the updated order
-	query := "SELECT id, customer_id, order_date, status, total_amount, created_at, updated_at FROM orders WHERE id = ?"
-	err = r.DB.QueryRowContext(ctx, query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at FROM orders WHERE id = ?"
+	err = r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -166,44 +193,235 @@ func (r *Order) Update(ctx context.Context, ID int64, request *orderDomainEntity
 	return &order, nil
 }
 
-func (r *Order) Create(ctx context.Context, request *orderDomainEntity.OrderRequest) error {
-	// Start transaction
-	tx, err := r.DB.Begin()
+func (r *Order) GetByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	order := orderDomainEntity.Order{}
+
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at FROM orders WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorOrderNotFound
+	}
 	if err != nil {
-		return err
+		r.log.ErrorLog(ctx, err)
+		return nil, err
 	}
 
-	result, err := r.DB.ExecContext(ctx, "INSERT INTO orders (customer_id, order_date, total_amount) VALUES (?, ?, ?)", request.CustomerID, request.OrderDate, request.TotalAmount)
+	return &order, nil
+}
+
+// GetDeletedByIdForUpdate reads a deleted order and, inside a transaction,
+// locks its row until the transaction ends.
+func (r *Order) GetDeletedByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	order := orderDomainEntity.Order{}
+
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at FROM orders WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt, &order.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorOrderNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetOpenByCustomerForUpdate reads the orders of a customer that have not
+// shipped yet and, inside a transaction, locks their rows until the
+// transaction ends.
+func (r *Order) GetOpenByCustomerForUpdate(ctx context.Context, customerID int64) ([]orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at FROM orders WHERE customer_id = ? AND deleted_at IS NULL AND status IN (?, ?, ?) ORDER BY id FOR UPDATE"
+	return r.queryOrders(ctx, query, customerID, orderDomainEntity.OrderStatusPending, orderDomainEntity.OrderStatusConfirmed, orderDomainEntity.OrderStatusProcessing)
+}
+
+// GetDeletedByCustomerForUpdate reads the orders deleted together with their
+// customer, they share its deleted_at, and locks them like
+// GetOpenByCustomerForUpdate.
+func (r *Order) GetDeletedByCustomerForUpdate(ctx context.Context, customerID int64, deletedAt time.Time) ([]orderDomainEntity.Order, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT id, customer_id, order_date, status, subtotal, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at FROM orders WHERE customer_id = ? AND deleted_at = ? ORDER BY id FOR UPDATE"
+	return r.queryOrders(ctx, query, customerID, deletedAt)
+}
+
+func (r *Order) queryOrders(ctx context.Context, query string, args ...interface{}) ([]orderDomainEntity.Order, error) {
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []orderDomainEntity.Order{}
+	for rows.Next() {
+		var order orderDomainEntity.Order
+		if err := rows.Scan(&order.ID, &order.CustomerID, &order.OrderDate, &order.Status, &order.Subtotal, &order.DiscountAmount, &order.TaxAmount, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt, &order.DeletedAt); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *Order) Restore(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE orders SET deleted_at = NULL WHERE id = ?", ID)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
 	}
 
+	return nil
+}
+
+// Purge hard deletes the orders deleted before the given time, their items
+// and status history go with them through ON DELETE CASCADE.
+func (r *Order) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "DELETE FROM orders WHERE deleted_at < ?", before)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (r *Order) Create(ctx context.Context, order *orderDomainEntity.Order) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO orders (customer_id, order_date, subtotal, discount_amount, tax_amount, total_amount) VALUES (?, ?, ?, ?, ?, ?)",
+		order.CustomerID, order.OrderDate, order.Subtotal, order.DiscountAmount, order.TaxAmount, order.TotalAmount)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
 	// Get ID of the inserted record
 	orderID, err := result.LastInsertId()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return orderID, nil
+}
+
+func (r *Order) UpdateTotals(ctx context.Context, order *orderDomainEntity.Order) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE orders SET subtotal = ?, discount_amount = ?, tax_amount = ?, total_amount = ? WHERE id = ?",
+		order.Subtotal, order.DiscountAmount, order.TaxAmount, order.TotalAmount, order.ID)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
 	}
 
-	// Insert order items
-	for _, item := range request.OrderItems {
-		_, err := tx.Exec("INSERT INTO order_items (order_id, product_name, quantity, price, total_price) VALUES (?, ?, ?, ?, ?)",
-			orderID, item.ProductName, item.Quantity, item.Price, item.TotalPrice)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
+	return nil
+}
+
+func (r *Order) CreateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO order_items (order_id, product_id, product_name, quantity, price, discount, total_price) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		orderID, item.ProductID, item.ProductName, item.Quantity, item.Price, item.Discount, item.TotalPrice)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Order) UpdateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE order_items SET quantity = ?, discount = ?, total_price = ? WHERE id = ? AND order_id = ?",
+		item.Quantity, item.Discount, item.TotalPrice, item.ID, orderID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Order) DeleteItem(ctx context.Context, orderID int64, itemID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "DELETE FROM order_items WHERE id = ? AND order_id = ?", itemID, orderID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (r *Order) GetCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	customer := customerDomainEntity.Customer{}
 
-	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ?"
-	err := r.DB.QueryRow(query, customerID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at FROM customers WHERE id = ? AND deleted_at IS NULL LOCK IN SHARE MODE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, customerID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorCustomerNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// GetOrderCustomer reads the customer of an order for display, deleted
+// customers included since their shipped orders are kept.
+func (r *Order) GetOrderCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	customer := customerDomainEntity.Customer{}
+
+	query := "SELECT id, full_name, address, phone_number, email, is_active, created_at, updated_at, deleted_at FROM customers WHERE id = ?"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, customerID).Scan(&customer.ID, &customer.FullName, &customer.Address, &customer.PhoneNumber, &customer.Email, &customer.IsActive, &customer.CreatedAt, &customer.UpdatedAt, &customer.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorCustomerNotFound
+	}
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -213,21 +431,22 @@ func (r *Order) GetCustomer(ctx context.Context, customerID int64) (*customerDom
 }
 
 func (r *Order) GetOrderItems(ctx context.Context, orderId int64) ([]orderDomainEntity.OrderItem, error) {
-	var args []interface{}
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
 
-	query := "SELECT id, product_name, price, quantity, total_price FROM order_items"
+	query := "SELECT id, order_id, COALESCE(product_id, 0), product_name, quantity, price, discount, total_price, created_at, updated_at FROM order_items WHERE order_id = ? ORDER BY id"
 
-	rows, err := r.DB.Query(query, args...)
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, orderId)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var orderItems []orderDomainEntity.OrderItem
+	orderItems := []orderDomainEntity.OrderItem{}
 	for rows.Next() {
 		var item orderDomainEntity.OrderItem
-		if err := rows.Scan(&item.ID, &item.ProductName, &item.Price, &item.TotalPrice, &item.Quantity); err != nil {
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.ProductName, &item.Quantity, &item.Price, &item.Discount, &item.TotalPrice, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			r.log.ErrorLog(ctx, err)
 			return nil, err
 		}
@@ -240,3 +459,72 @@ func (r *Order) GetOrderItems(ctx context.Context, orderId int64) ([]orderDomain
 
 	return orderItems, nil
 }
+
+func (r *Order) UpdateStatus(ctx context.Context, ID int64, fromStatus string, toStatus string) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	// Only move the order when it is still in the status the transition was
+	// validated against, so concurrent transitions cannot both succeed.
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE orders SET status = ? WHERE id = ? AND status = ? AND deleted_at IS NULL", toStatus, ID, fromStatus)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	if affected == 0 {
+		return errorHelper.ErrorOrderStatusConflict
+	}
+
+	return nil
+}
+
+func (r *Order) CreateStatusHistory(ctx context.Context, history *orderDomainEntity.OrderStatusHistory) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO order_status_history (order_id, from_status, to_status, reason, changed_by) VALUES (?, ?, ?, ?, ?)",
+		history.OrderID, history.FromStatus, history.ToStatus, history.Reason, history.ChangedBy)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Order) GetStatusHistory(ctx context.Context, orderID int64) ([]orderDomainEntity.OrderStatusHistory, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT id, order_id, from_status, to_status, reason, changed_by, created_at FROM order_status_history WHERE order_id = ? ORDER BY created_at, id"
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, orderID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []orderDomainEntity.OrderStatusHistory{}
+	for rows.Next() {
+		var history orderDomainEntity.OrderStatusHistory
+		if err := rows.Scan(&history.ID, &history.OrderID, &history.FromStatus, &history.ToStatus, &history.Reason, &history.ChangedBy, &history.CreatedAt); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,141 @@
+package orderUsecase
+
+import (
+	"fmt"
+
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+)
+
+// patchProductIDs returns the products a patch can take stock of: those of
+// the added items and of the current items whose quantity grows. Items
+// created before the product catalog existed hold no stock and are left out.
+func patchProductIDs(current []orderDomainEntity.OrderItem, request *orderDomainEntity.OrderItemsPatchRequest) []int64 {
+	productIDs := requestedProductIDs(request.Add)
+
+	itemIndex := make(map[int64]int, len(current))
+	for i, item := range current {
+		itemIndex[item.ID] = i
+	}
+
+	for _, itemRequest := range request.Update {
+		index, exists := itemIndex[itemRequest.ID]
+		if !exists || current[index].ProductID == 0 || itemRequest.Quantity <= current[index].Quantity {
+			continue
+		}
+
+		productIDs = append(productIDs, current[index].ProductID)
+	}
+
+	return productIDs
+}
+
+// planOrderItemChanges applies a patch to the current items of an order. It
+// returns the writes needed to store the resulting items and the new order
+// breakdown. added holds the items of request.Add,
+// already priced from the product catalog.
+func planOrderItemChanges(current []orderDomainEntity.OrderItem, request *orderDomainEntity.OrderItemsPatchRequest, added []orderDomainEntity.OrderItem, taxRate decimal.Decimal) (*orderDomainEntity.OrderItemChanges, *orderTotals, error) {
+	validation := validationHelper.New()
+	changes := &orderDomainEntity.OrderItemChanges{StockDeltas: map[int64]int{}}
+
+	items := make([]orderDomainEntity.OrderItem, len(current))
+	copy(items, current)
+
+	itemIndex := make(map[int64]int, len(items))
+	for i, item := range items {
+		itemIndex[item.ID] = i
+	}
+
+	removed := map[int64]bool{}
+	for i, ID := range request.Remove {
+		index, exists := itemIndex[ID]
+		if !exists {
+			validation.Add(fmt.Sprintf("remove[%d]", i), validationHelper.RuleExists, "order item not found on this order")
+			continue
+		}
+		if removed[ID] {
+			continue
+		}
+
+		removed[ID] = true
+		changes.Removed = append(changes.Removed, items[index])
+		changes.StockDeltas[items[index].ProductID] -= items[index].Quantity
+	}
+
+	updated := map[int64]bool{}
+	for i, itemRequest := range request.Update {
+		index, exists := itemIndex[itemRequest.ID]
+		if !exists || removed[itemRequest.ID] {
+			validation.Add(fmt.Sprintf("update[%d].id", i), validationHelper.RuleExists, "order item not found on this order")
+			continue
+		}
+		if updated[itemRequest.ID] {
+			validation.Add(fmt.Sprintf("update[%d].id", i), validationHelper.RuleUnique, "order item can only be updated once")
+			continue
+		}
+
+		item := items[index]
+		item.Quantity = itemRequest.Quantity
+		if itemRequest.Discount != nil {
+			item.Discount = *itemRequest.Discount
+		}
+		if rule, message := lineDiscountError(&item); rule != "" {
+			validation.Add(fmt.Sprintf("update[%d].discount", i), rule, message)
+			continue
+		}
+
+		updated[item.ID] = true
+		changes.StockDeltas[item.ProductID] += item.Quantity - items[index].Quantity
+		items[index] = item
+	}
+
+	for i := range added {
+		if rule, message := lineDiscountError(&added[i]); rule != "" {
+			validation.Add(fmt.Sprintf("add[%d].discount", i), rule, message)
+			continue
+		}
+
+		changes.StockDeltas[added[i].ProductID] += added[i].Quantity
+	}
+
+	result := make([]orderDomainEntity.OrderItem, 0, len(items)+len(added))
+	for _, item := range items {
+		if !removed[item.ID] {
+			result = append(result, item)
+		}
+	}
+	result = append(result, added...)
+
+	if len(result) == 0 {
+		validation.Add("remove", validationHelper.RuleMin, "order must keep at least one item")
+	}
+
+	if err := validation.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	totals, err := calculateOrderTotals(result, taxRate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, item := range result {
+		switch {
+		case item.ID == 0:
+			changes.Added = append(changes.Added, item)
+		case updated[item.ID]:
+			changes.Updated = append(changes.Updated, item)
+		}
+	}
+
+	// items created before the product catalog existed hold no stock
+	delete(changes.StockDeltas, 0)
+	for productID, delta := range changes.StockDeltas {
+		if delta == 0 {
+			delete(changes.StockDeltas, productID)
+		}
+	}
+
+	return changes, totals, nil
+}
@@ -0,0 +1,56 @@
+package orderUsecase
+
+import (
+	"errors"
+	"testing"
+
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func currentOrderItems() []orderDomainEntity.OrderItem {
+	return []orderDomainEntity.OrderItem{
+		{ID: 1, ProductID: 10, Quantity: 2, Price: decimal.RequireFromString("5.00")},
+		{ID: 2, ProductID: 20, Quantity: 1, Price: decimal.RequireFromString("7.50")},
+	}
+}
+
+func TestPlanOrderItemChanges(t *testing.T) {
+	discount := decimal.RequireFromString("1.00")
+	request := &orderDomainEntity.OrderItemsPatchRequest{
+		Update: []orderDomainEntity.OrderItemUpdateRequest{{ID: 1, Quantity: 3, Discount: &discount}},
+		Remove: []int64{2},
+	}
+	added := []orderDomainEntity.OrderItem{{ProductID: 30, Quantity: 4, Price: decimal.RequireFromString("2.25")}}
+
+	changes, totals, err := planOrderItemChanges(currentOrderItems(), request, added, decimal.Zero)
+	assert.NoError(t, err)
+
+	assert.Len(t, changes.Updated, 1)
+	assert.Equal(t, "14.00", changes.Updated[0].TotalPrice.StringFixed(2))
+	assert.Len(t, changes.Removed, 1)
+	assert.Equal(t, int64(2), changes.Removed[0].ID)
+	assert.Len(t, changes.Added, 1)
+	assert.Equal(t, "9.00", changes.Added[0].TotalPrice.StringFixed(2))
+	assert.Equal(t, map[int64]int{10: 1, 20: -1, 30: 4}, changes.StockDeltas)
+	assert.Equal(t, "23.00", totals.TotalAmount.StringFixed(2))
+}
+
+func TestPlanOrderItemChangesRejectsUnknownItems(t *testing.T) {
+	request := &orderDomainEntity.OrderItemsPatchRequest{
+		Update: []orderDomainEntity.OrderItemUpdateRequest{{ID: 99, Quantity: 1}},
+		Remove: []int64{1, 2},
+	}
+
+	_, _, err := planOrderItemChanges(currentOrderItems(), request, nil, decimal.Zero)
+
+	var validationErr *errorHelper.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []errorHelper.FieldError{
+		{Field: "update[0].id", Rule: validationHelper.RuleExists, Message: "order item not found on this order"},
+		{Field: "remove", Rule: validationHelper.RuleMin, Message: "order must keep at least one item"},
+	}, validationErr.Fields)
+}
@@ -0,0 +1,78 @@
+package orderUsecase
+
+import (
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+)
+
+// orderStatusTransitions lists, for every order status, the statuses it may
+// move to. Statuses without an entry are terminal.
+var orderStatusTransitions = map[string][]string{
+	orderDomainEntity.OrderStatusPending: {
+		orderDomainEntity.OrderStatusConfirmed,
+		orderDomainEntity.OrderStatusCancelled,
+	},
+	orderDomainEntity.OrderStatusConfirmed: {
+		orderDomainEntity.OrderStatusProcessing,
+		orderDomainEntity.OrderStatusCancelled,
+	},
+	orderDomainEntity.OrderStatusProcessing: {
+		orderDomainEntity.OrderStatusShipped,
+		orderDomainEntity.OrderStatusCancelled,
+	},
+	orderDomainEntity.OrderStatusShipped: {
+		orderDomainEntity.OrderStatusDelivered,
+		orderDomainEntity.OrderStatusReturned,
+	},
+	orderDomainEntity.OrderStatusDelivered: {
+		orderDomainEntity.OrderStatusReturned,
+	},
+}
+
+// isKnownOrderStatus reports whether status is one of the values allowed by
+// the orders.status check constraint.
+func isKnownOrderStatus(status string) bool {
+	switch status {
+	case orderDomainEntity.OrderStatusPending,
+		orderDomainEntity.OrderStatusConfirmed,
+		orderDomainEntity.OrderStatusProcessing,
+		orderDomainEntity.OrderStatusShipped,
+		orderDomainEntity.OrderStatusDelivered,
+		orderDomainEntity.OrderStatusCancelled,
+		orderDomainEntity.OrderStatusReturned:
+		return true
+	}
+
+	return false
+}
+
+// canTransitionOrderStatus reports whether an order may move from one status
+// to another.
+func canTransitionOrderStatus(from, to string) bool {
+	for _, next := range orderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEditableOrderStatus reports whether the line items of an order in the
+// given status may still change. Once processing starts the items are fixed.
+func isEditableOrderStatus(status string) bool {
+	return status == orderDomainEntity.OrderStatusPending || status == orderDomainEntity.OrderStatusConfirmed
+}
+
+// holdsStock reports whether an order in the given status keeps the stock of
+// its items reserved. Shipped orders took it out of the warehouse, cancelled
+// ones gave it back.
+func holdsStock(status string) bool {
+	switch status {
+	case orderDomainEntity.OrderStatusPending,
+		orderDomainEntity.OrderStatusConfirmed,
+		orderDomainEntity.OrderStatusProcessing:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package orderUsecase
+
+import (
+	"testing"
+
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionOrderStatusAllowsLegalMoves(t *testing.T) {
+	assert.True(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusPending, orderDomainEntity.OrderStatusConfirmed))
+	assert.True(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusShipped, orderDomainEntity.OrderStatusDelivered))
+	assert.True(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusDelivered, orderDomainEntity.OrderStatusReturned))
+}
+
+func TestCanTransitionOrderStatusRejectsIllegalMoves(t *testing.T) {
+	assert.False(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusPending, orderDomainEntity.OrderStatusDelivered))
+	assert.False(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusDelivered, orderDomainEntity.OrderStatusPending))
+	assert.False(t, canTransitionOrderStatus(orderDomainEntity.OrderStatusPending, orderDomainEntity.OrderStatusPending))
+}
+
+func TestCanTransitionOrderStatusTerminalStatuses(t *testing.T) {
+	for _, status := range []string{orderDomainEntity.OrderStatusCancelled, orderDomainEntity.OrderStatusReturned} {
+		assert.Empty(t, orderStatusTransitions[status])
+		assert.False(t, canTransitionOrderStatus(status, orderDomainEntity.OrderStatusPending))
+	}
+}
+
+func TestIsKnownOrderStatus(t *testing.T) {
+	assert.True(t, isKnownOrderStatus(orderDomainEntity.OrderStatusProcessing))
+	assert.False(t, isKnownOrderStatus("processing"))
+	assert.False(t, isKnownOrderStatus(""))
+}
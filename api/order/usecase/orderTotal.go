@@ -0,0 +1,103 @@
+package orderUsecase
+
+import (
+	"fmt"
+
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+)
+
+// moneyPlaces is the number of decimal places amounts are stored with.
+const moneyPlaces = 2
+
+type orderTotals struct {
+	Subtotal       decimal.Decimal
+	DiscountAmount decimal.Decimal
+	TaxAmount      decimal.Decimal
+	TotalAmount    decimal.Decimal
+}
+
+// parseTaxRate reads the configured tax rate, an empty value means no tax.
+func parseTaxRate(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	rate, err := decimal.NewFromString(value)
+	if err != nil || rate.IsNegative() {
+		return decimal.Zero, errorHelper.ErrorOrderTaxRateInvalid
+	}
+
+	return rate, nil
+}
+
+func lineAmount(item *orderDomainEntity.OrderItem) decimal.Decimal {
+	return item.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+}
+
+// lineDiscountError describes which rule the discount of an item breaks and
+// why, it returns empty strings for a valid discount.
+func lineDiscountError(item *orderDomainEntity.OrderItem) (string, string) {
+	discount := item.Discount
+
+	switch {
+	case discount.IsNegative():
+		return validationHelper.RuleMin, "discount can not be negative"
+	case !discount.Equal(discount.Round(moneyPlaces)):
+		return validationHelper.RuleDecimal, fmt.Sprintf("discount can not have more than %d decimal places", moneyPlaces)
+	case discount.GreaterThan(lineAmount(item)):
+		return validationHelper.RuleMax, "discount can not exceed the line amount"
+	}
+
+	return "", ""
+}
+
+// calculateOrderTotals fills the line total of every item and returns the
+// order breakdown. Tax is charged on the subtotal after discounts.
+func calculateOrderTotals(items []orderDomainEntity.OrderItem, taxRate decimal.Decimal) (*orderTotals, error) {
+	validation := validationHelper.New()
+	totals := &orderTotals{}
+
+	for i := range items {
+		lineAmount := lineAmount(&items[i])
+		if rule, message := lineDiscountError(&items[i]); rule != "" {
+			validation.Add(fmt.Sprintf("order_items[%d].discount", i), rule, message)
+			continue
+		}
+
+		discount := items[i].Discount
+		items[i].TotalPrice = lineAmount.Sub(discount)
+		totals.Subtotal = totals.Subtotal.Add(lineAmount)
+		totals.DiscountAmount = totals.DiscountAmount.Add(discount)
+	}
+
+	if err := validation.Err(); err != nil {
+		return nil, err
+	}
+
+	taxable := totals.Subtotal.Sub(totals.DiscountAmount)
+	totals.TaxAmount = taxable.Mul(taxRate).Round(moneyPlaces)
+	totals.TotalAmount = taxable.Add(totals.TaxAmount)
+
+	return totals, nil
+}
+
+// compareClientTotals rejects client supplied totals that differ from the
+// computed ones. Totals the client did not send are not checked.
+func compareClientTotals(request *orderDomainEntity.OrderRequest, items []orderDomainEntity.OrderItem, totals *orderTotals) error {
+	validation := validationHelper.New()
+
+	for i, itemRequest := range request.OrderItems {
+		if itemRequest.TotalPrice != nil && !itemRequest.TotalPrice.Equal(items[i].TotalPrice) {
+			validation.Add(fmt.Sprintf("order_items[%d].total_price", i), validationHelper.RuleMatch, fmt.Sprintf("total price must be %s", items[i].TotalPrice.StringFixed(moneyPlaces)))
+		}
+	}
+
+	if request.TotalAmount != nil && !request.TotalAmount.Equal(totals.TotalAmount) {
+		validation.Add("total_amount", validationHelper.RuleMatch, fmt.Sprintf("total amount must be %s", totals.TotalAmount.StringFixed(moneyPlaces)))
+	}
+
+	return validation.Err()
+}
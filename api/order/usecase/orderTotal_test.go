@@ -0,0 +1,74 @@
+package orderUsecase
+
+import (
+	"errors"
+	"testing"
+
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateOrderTotals(t *testing.T) {
+	items := []orderDomainEntity.OrderItem{
+		{Quantity: 3, Price: decimal.RequireFromString("0.10")},
+		{Quantity: 2, Price: decimal.RequireFromString("19.99"), Discount: decimal.RequireFromString("5.00")},
+	}
+
+	totals, err := calculateOrderTotals(items, decimal.RequireFromString("0.11"))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.30", items[0].TotalPrice.StringFixed(2))
+	assert.Equal(t, "34.98", items[1].TotalPrice.StringFixed(2))
+	assert.Equal(t, "40.28", totals.Subtotal.StringFixed(2))
+	assert.Equal(t, "5.00", totals.DiscountAmount.StringFixed(2))
+	assert.Equal(t, "3.88", totals.TaxAmount.StringFixed(2))
+	assert.Equal(t, "39.16", totals.TotalAmount.StringFixed(2))
+}
+
+func TestCalculateOrderTotalsRejectsInvalidDiscounts(t *testing.T) {
+	items := []orderDomainEntity.OrderItem{
+		{Quantity: 1, Price: decimal.RequireFromString("10.00"), Discount: decimal.RequireFromString("-1")},
+		{Quantity: 1, Price: decimal.RequireFromString("10.00"), Discount: decimal.RequireFromString("10.01")},
+	}
+
+	_, err := calculateOrderTotals(items, decimal.Zero)
+
+	var validationErr *errorHelper.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Fields, 2)
+	assert.Equal(t, "order_items[0].discount", validationErr.Fields[0].Field)
+	assert.Equal(t, "order_items[1].discount", validationErr.Fields[1].Field)
+	assert.Equal(t, validationHelper.RuleMin, validationErr.Fields[0].Rule)
+	assert.Equal(t, validationHelper.RuleMax, validationErr.Fields[1].Rule)
+}
+
+func TestCompareClientTotals(t *testing.T) {
+	items := []orderDomainEntity.OrderItem{{TotalPrice: decimal.RequireFromString("10")}}
+	totals := &orderTotals{TotalAmount: decimal.RequireFromString("11.10")}
+
+	matching := decimal.RequireFromString("11.1")
+	request := &orderDomainEntity.OrderRequest{
+		TotalAmount: &matching,
+		OrderItems:  []orderDomainEntity.OrderItemRequest{{}},
+	}
+	assert.NoError(t, compareClientTotals(request, items, totals))
+
+	wrong := decimal.RequireFromString("9.99")
+	request.OrderItems[0].TotalPrice = &wrong
+	err := compareClientTotals(request, items, totals)
+
+	var validationErr *errorHelper.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []errorHelper.FieldError{{Field: "order_items[0].total_price", Rule: validationHelper.RuleMatch, Message: "total price must be 10.00"}}, validationErr.Fields)
+}
+
+func TestParseTaxRate(t *testing.T) {
+	rate, err := parseTaxRate("")
+	assert.NoError(t, err)
+	assert.True(t, rate.IsZero())
+
+	_, err = parseTaxRate("-0.1")
+	assert.ErrorIs(t, err, errorHelper.ErrorOrderTaxRateInvalid)
+}
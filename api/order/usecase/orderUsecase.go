@@ -3,153 +3,855 @@ package orderUsecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"time"
 
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	auditUsecase "github.com/ahsansandiah/dpo-test/api/audit/usecase"
 	orderDomainInterface "github.com/ahsansandiah/dpo-test/api/order/domain"
 	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
 	orderRepository "github.com/ahsansandiah/dpo-test/api/order/repository"
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	productRepository "github.com/ahsansandiah/dpo-test/api/product/repository"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	softDeleteHelper "github.com/ahsansandiah/dpo-test/helpers/softdelete"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
 )
 
 type OrderUsecase struct {
-	log  log.Log
-	cfg  *config.Config
-	repo orderDomainInterface.OrderRepository
+	log         log.Log
+	cfg         *config.Config
+	trx         transactionDatabase.Transaction
+	repo        orderDomainInterface.OrderRepository
+	productRepo productDomainInterface.ProductRepository
+	audit       auditDomainInterface.AuditUsecase
+	outbox      outbox.Outbox
+	metrics     metrics.Metrics
 }
 
 func NewOrderUsecase(mgr manager.Manager) orderDomainInterface.OrderUsecase {
 	usecase := new(OrderUsecase)
 	usecase.log = mgr.GetLog()
 	usecase.cfg = mgr.GetConfig()
+	usecase.trx = mgr.GetTransaction()
 	usecase.repo = orderRepository.NewOrderRepository(mgr)
+	usecase.productRepo = productRepository.NewProductRepository(mgr)
+	usecase.audit = auditUsecase.NewAuditUsecase(mgr)
+	usecase.outbox = mgr.GetOutbox()
+	usecase.metrics = mgr.GetMetrics()
 
 	return usecase
 }
 
-func (u *OrderUsecase) GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) ([]orderDomainEntity.OrderResponse, error) {
+func (u *OrderUsecase) GetAll(ctx context.Context, filter *orderDomainEntity.OrderFilter) (*orderDomainEntity.OrderListRespone, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if filter.Deleted != softDeleteHelper.ScopeActive {
+		principal, err := principalAuth.Authenticated(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !principal.HasPermission(orderDomainEntity.PermissionOrderRestore) {
+			return nil, errorHelper.ErrorDeletedForbidden
+		}
+	}
+
+	sortBy, err := paginateHelper.ValidateSortBy(filter.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	filter.SortBy = sortBy
+	filter.LIMIT = paginateHelper.ValidateLimit(filter.LIMIT)
+
+	if filter.Cursor != "" {
+		cursor, err := paginateHelper.ParseCursor(filter.Cursor, u.cfg.PaginateCursorSecret)
+		if err != nil {
+			return nil, errorHelper.Wrap(err, "Error parsing cursor")
+		}
+		filter.Keyset = cursor
+		filter.SortBy = cursor.SortBy
+	}
+
+	// fetch one extra order to know whether another page exists
+	limit := filter.LIMIT
+	filter.LIMIT = limit + 1
 	orders, err := u.repo.GetAll(ctx, filter)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		return nil, err
+		return nil, errorHelper.Wrap(err, "Error fetching orders")
 	}
+	filter.LIMIT = limit
 
-	return orders, nil
+	hasMore := len(orders) > limit
+	if hasMore {
+		orders = orders[:limit]
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+
+	var first, last *paginateHelper.Cursor
+	if len(orders) > 0 {
+		firstCursor := paginateHelper.NewCursor(filter.SortBy, orders[0].CreatedAt, orders[0].ID)
+		lastCursor := paginateHelper.NewCursor(filter.SortBy, orders[len(orders)-1].CreatedAt, orders[len(orders)-1].ID)
+		first, last = &firstCursor, &lastCursor
+	}
+
+	paginate, err := paginateHelper.NewPaginate(u.cfg.PaginateCursorSecret, filter.Keyset, hasMore, first, last)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error paginating orders")
+	}
+
+	result := &orderDomainEntity.OrderListRespone{
+		Order:    orders,
+		Paginate: paginate,
+	}
+
+	return result, nil
 }
 
 func (u *OrderUsecase) Delete(ctx context.Context, ID int64) error {
-	order, err := u.repo.GetById(ctx, ID)
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		before, err := u.orderResponse(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		if holdsStock(order.Status) {
+			if err := u.releaseStock(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := u.repo.Delete(ctx, order.ID, time.Now()); err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, order.ID, auditDomainEntity.ActionDelete, before, nil); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, order.ID, orderDomainEntity.EventOrderDeleted, before)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order details")
-		return errMsg
+		return errorHelper.Wrap(err, "Error deleting order")
 	}
 
-	err = u.repo.Delete(ctx, order.ID)
+	return nil
+}
+
+// Restore brings back a deleted order. The customer of the order has to be
+// restored first.
+func (u *OrderUsecase) Restore(ctx context.Context, ID int64) (*orderDomainEntity.OrderResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	var result *orderDomainEntity.OrderResponse
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := u.repo.GetDeletedByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = u.repo.GetCustomer(ctx, order.CustomerID)
+		if errors.Is(err, errorHelper.ErrorCustomerNotFound) {
+			return errorHelper.ErrorCustomerDeleted
+		}
+		if err != nil {
+			return err
+		}
+
+		before, err := u.orderResponse(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		// the stock was given back when the order was deleted
+		if holdsStock(order.Status) {
+			if err := u.takeStock(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := u.repo.Restore(ctx, ID); err != nil {
+			return err
+		}
+
+		result, err = u.GetByID(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, ID, auditDomainEntity.ActionRestore, before, result); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, ID, orderDomainEntity.EventOrderRestored, result)
+	})
+	if errors.Is(err, errorHelper.ErrorInsufficientStock) {
+		return nil, err
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error deleting order")
-		return errMsg
+		return nil, errorHelper.Wrap(err, "Error restoring order")
 	}
 
-	return nil
+	return result, nil
+}
+
+func (u *OrderUsecase) DeleteOfCustomer(ctx context.Context, customerID int64, deletedAt time.Time) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	return u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		orders, err := u.repo.GetOpenByCustomerForUpdate(ctx, customerID)
+		if err != nil {
+			return err
+		}
+
+		for i := range orders {
+			before, err := u.orderResponse(ctx, &orders[i])
+			if err != nil {
+				return err
+			}
+
+			if err := u.releaseStock(ctx, orders[i].ID); err != nil {
+				return err
+			}
+
+			if err := u.repo.Delete(ctx, orders[i].ID, deletedAt); err != nil {
+				return err
+			}
+
+			if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, orders[i].ID, auditDomainEntity.ActionDelete, before, nil); err != nil {
+				return err
+			}
+
+			if err := u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, orders[i].ID, orderDomainEntity.EventOrderDeleted, before); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *OrderUsecase) RestoreOfCustomer(ctx context.Context, customerID int64, deletedAt time.Time) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	return u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		orders, err := u.repo.GetDeletedByCustomerForUpdate(ctx, customerID, deletedAt)
+		if err != nil {
+			return err
+		}
+
+		for i := range orders {
+			before, err := u.orderResponse(ctx, &orders[i])
+			if err != nil {
+				return err
+			}
+
+			if holdsStock(orders[i].Status) {
+				if err := u.takeStock(ctx, orders[i].ID); err != nil {
+					return err
+				}
+			}
+
+			if err := u.repo.Restore(ctx, orders[i].ID); err != nil {
+				return err
+			}
+
+			result, err := u.GetByID(ctx, orders[i].ID)
+			if err != nil {
+				return err
+			}
+
+			if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, orders[i].ID, auditDomainEntity.ActionRestore, before, result); err != nil {
+				return err
+			}
+
+			if err := u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, orders[i].ID, orderDomainEntity.EventOrderRestored, result); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (u *OrderUsecase) GetByID(ctx context.Context, ID int64) (*orderDomainEntity.OrderResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	// get order
 	order, err := u.repo.GetById(ctx, ID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error fetching order")
 	}
 
-	customer, err := u.repo.GetCustomer(ctx, int64(order.CustomerID))
+	return u.orderResponse(ctx, order)
+}
+
+// orderResponse adds the customer and the items to an order.
+func (u *OrderUsecase) orderResponse(ctx context.Context, order *orderDomainEntity.Order) (*orderDomainEntity.OrderResponse, error) {
+	customer, err := u.repo.GetOrderCustomer(ctx, order.CustomerID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error fetching order")
 	}
 
 	// get order items
 	orderItems, err := u.repo.GetOrderItems(ctx, order.ID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order items")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error fetching order items")
 	}
 
 	result := orderDomainEntity.OrderResponse{
-		ID:          order.ID,
-		OrderDate:   order.OrderDate,
-		TotalAmount: order.TotalAmount,
-		Status:      order.Status,
-		Customer:    customer,
-		Items:       orderItems,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
+		ID:             order.ID,
+		OrderDate:      order.OrderDate,
+		Subtotal:       order.Subtotal,
+		DiscountAmount: order.DiscountAmount,
+		TaxAmount:      order.TaxAmount,
+		TotalAmount:    order.TotalAmount,
+		Status:         order.Status,
+		Customer:       customer,
+		Items:          orderItems,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+		DeletedAt:      order.DeletedAt,
 	}
 
 	return &result, nil
 }
 
 func (u *OrderUsecase) Update(ctx context.Context, ID int64, request *orderDomainEntity.OrderUpdateRequest) (*orderDomainEntity.OrderResponse, error) {
-	order, err := u.repo.GetById(ctx, ID)
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	var result *orderDomainEntity.OrderResponse
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		before, err := u.orderResponse(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		if request.OrderDate.IsZero() {
+			request.OrderDate = order.OrderDate
+		}
+
+		if _, err := u.repo.Update(ctx, ID, request); err != nil {
+			return err
+		}
+
+		result, err = u.GetByID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, order.ID, auditDomainEntity.ActionUpdate, before, result); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, order.ID, orderDomainEntity.EventOrderUpdated, result)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order details")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error update order")
 	}
 
-	if request.TotalAmount <= 0 {
-		request.TotalAmount = order.TotalAmount
+	return result, nil
+}
+
+func (u *OrderUsecase) Create(ctx context.Context, request *orderDomainEntity.OrderRequest) (*orderDomainEntity.OrderResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	taxRate, err := parseTaxRate(u.cfg.OrderTaxRate)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
 	}
 
-	if request.OrderDate.IsZero() {
-		request.OrderDate = order.OrderDate
+	// price the items, reserve stock and create the order with its items
+	// atomically, the product rows stay locked until the order is stored
+	var result *orderDomainEntity.OrderResponse
+	err = u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the customer row stays share locked, so it can not be deactivated
+		// or deleted before the order is stored
+		if err := u.ValidateCustomer(ctx, request.CustomerID); err != nil {
+			return err
+		}
+
+		products, err := u.lockProducts(ctx, requestedProductIDs(request.OrderItems))
+		if err != nil {
+			return err
+		}
+
+		items := priceOrderItems(request.OrderItems, products)
+
+		totals, err := calculateOrderTotals(items, taxRate)
+		if err != nil {
+			return err
+		}
+
+		if err := compareClientTotals(request, items, totals); err != nil {
+			return err
+		}
+
+		stockDeltas := map[int64]int{}
+		for _, item := range items {
+			stockDeltas[item.ProductID] += item.Quantity
+		}
+
+		if err := u.reserveStock(ctx, stockDeltas); err != nil {
+			return err
+		}
+
+		order := &orderDomainEntity.Order{
+			CustomerID:     request.CustomerID,
+			OrderDate:      request.OrderDate,
+			Subtotal:       totals.Subtotal,
+			DiscountAmount: totals.DiscountAmount,
+			TaxAmount:      totals.TaxAmount,
+			TotalAmount:    totals.TotalAmount,
+		}
+
+		orderID, err := u.repo.Create(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		for i := range items {
+			if err := u.repo.CreateItem(ctx, orderID, &items[i]); err != nil {
+				return err
+			}
+		}
+
+		result, err = u.GetByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, orderID, auditDomainEntity.ActionCreate, nil, result); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, orderID, orderDomainEntity.EventOrderCreated, result)
+	})
+	if isOrderItemsError(err) {
+		return nil, err
+	}
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error inserting order")
 	}
 
-	_, err = u.repo.Update(ctx, ID, request)
+	u.metrics.OrderCreated(result.TotalAmount.InexactFloat64())
+
+	return result, nil
+}
+
+func (u *OrderUsecase) UpdateItems(ctx context.Context, ID int64, request *orderDomainEntity.OrderItemsPatchRequest) (*orderDomainEntity.OrderResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	taxRate, err := parseTaxRate(u.cfg.OrderTaxRate)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error update order")
-		return nil, errMsg
+		return nil, err
 	}
 
-	result, err := u.GetByID(ctx, order.ID)
+	var result *orderDomainEntity.OrderResponse
+	err = u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the order stays locked, so its status can not change underneath us
+		order, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if !isEditableOrderStatus(order.Status) {
+			return errorHelper.ErrorOrderNotEditable
+		}
+
+		before, err := u.orderResponse(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		currentItems, err := u.repo.GetOrderItems(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		// lock every product the patch can take stock of up front, lines
+		// it leaves alone may point at products deleted since
+		products, err := u.lockProducts(ctx, patchProductIDs(currentItems, request))
+		if err != nil {
+			return err
+		}
+
+		addedItems := priceOrderItems(request.Add, products)
+
+		changes, totals, err := planOrderItemChanges(currentItems, request, addedItems, taxRate)
+		if err != nil {
+			return err
+		}
+
+		if err := u.reserveStock(ctx, changes.StockDeltas); err != nil {
+			return err
+		}
+
+		for _, item := range changes.Removed {
+			if err := u.repo.DeleteItem(ctx, order.ID, item.ID); err != nil {
+				return err
+			}
+		}
+
+		for i := range changes.Updated {
+			if err := u.repo.UpdateItem(ctx, order.ID, &changes.Updated[i]); err != nil {
+				return err
+			}
+		}
+
+		for i := range changes.Added {
+			if err := u.repo.CreateItem(ctx, order.ID, &changes.Added[i]); err != nil {
+				return err
+			}
+		}
+
+		order.Subtotal = totals.Subtotal
+		order.DiscountAmount = totals.DiscountAmount
+		order.TaxAmount = totals.TaxAmount
+		order.TotalAmount = totals.TotalAmount
+
+		if err := u.repo.UpdateTotals(ctx, order); err != nil {
+			return err
+		}
+
+		result, err = u.GetByID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, order.ID, auditDomainEntity.ActionUpdate, before, result); err != nil {
+			return err
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, order.ID, orderDomainEntity.EventOrderUpdated, result)
+	})
+	if errors.Is(err, errorHelper.ErrorOrderNotEditable) || isOrderItemsError(err) {
+		return nil, err
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching order details")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error updating order items")
 	}
 
 	return result, nil
 }
 
-func (u *OrderUsecase) Create(ctx context.Context, request *orderDomainEntity.OrderRequest) (*orderDomainEntity.OrderRequest, error) {
-	// check customer
-	if !u.ValidateCustomer(ctx, request.CustomerID) {
-		errMsg := errors.New("Error customer not found")
-		return nil, errMsg
+// lockProducts reads the given products, every one once and in id order.
+// Inside a transaction the product rows stay locked, so the prices and the
+// stock can not change before the order is stored, and concurrent orders
+// lock them in the same sequence.
+func (u *OrderUsecase) lockProducts(ctx context.Context, productIDs []int64) (map[int64]*productDomainEntity.Product, error) {
+	sorted := make([]int64, len(productIDs))
+	copy(sorted, productIDs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	products := make(map[int64]*productDomainEntity.Product, len(sorted))
+	for _, productID := range sorted {
+		if _, locked := products[productID]; locked {
+			continue
+		}
+
+		product, err := u.productRepo.GetByIdForUpdate(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		products[productID] = product
+	}
+
+	return products, nil
+}
+
+func requestedProductIDs(requests []orderDomainEntity.OrderItemRequest) []int64 {
+	productIDs := make([]int64, 0, len(requests))
+	for _, itemRequest := range requests {
+		productIDs = append(productIDs, itemRequest.ProductID)
+	}
+
+	return productIDs
+}
+
+// priceOrderItems builds order items from a request, taking the name and
+// price of every product from the locked catalog rows.
+func priceOrderItems(requests []orderDomainEntity.OrderItemRequest, products map[int64]*productDomainEntity.Product) []orderDomainEntity.OrderItem {
+	items := make([]orderDomainEntity.OrderItem, 0, len(requests))
+	for _, itemRequest := range requests {
+		product := products[itemRequest.ProductID]
+		items = append(items, orderDomainEntity.OrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Quantity:    itemRequest.Quantity,
+			Price:       product.UnitPrice,
+			Discount:    itemRequest.Discount,
+		})
+	}
+
+	return items
+}
+
+// reserveStock takes stock for every product with a positive delta and gives
+// it back for every negative one. Products are handled in id order, Create
+// and UpdateItems lock them all with lockProducts first, so there the rows
+// read here are already held by the transaction.
+func (u *OrderUsecase) reserveStock(ctx context.Context, stockDeltas map[int64]int) error {
+	productIDs := make([]int64, 0, len(stockDeltas))
+	for productID := range stockDeltas {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+	for _, productID := range productIDs {
+		delta := stockDeltas[productID]
+		if delta > 0 {
+			product, err := u.productRepo.GetByIdForUpdate(ctx, productID)
+			if err != nil {
+				return err
+			}
+
+			if product.Stock < delta {
+				return fmt.Errorf("%w for product %s", errorHelper.ErrorInsufficientStock, product.SKU)
+			}
+		}
+
+		if err := u.productRepo.AdjustStock(ctx, productID, -delta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseStock gives back the stock reserved for the items of an order.
+func (u *OrderUsecase) releaseStock(ctx context.Context, orderID int64) error {
+	return u.moveStock(ctx, orderID, -1)
+}
+
+// takeStock reserves the stock for the items of an order again. Products
+// deleted in the meantime are not sold anymore, their items are left without
+// stock instead of failing the restore.
+func (u *OrderUsecase) takeStock(ctx context.Context, orderID int64) error {
+	return u.moveStock(ctx, orderID, 1)
+}
+
+func (u *OrderUsecase) moveStock(ctx context.Context, orderID int64, sign int) error {
+	items, err := u.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	stockDeltas := make(map[int64]int, len(items))
+	for _, item := range items {
+		// items created before the product catalog existed hold no stock
+		if item.ProductID == 0 {
+			continue
+		}
+		stockDeltas[item.ProductID] += sign * item.Quantity
+	}
+
+	if sign > 0 {
+		if err := u.dropDeletedProducts(ctx, stockDeltas); err != nil {
+			return err
+		}
+	}
+
+	return u.reserveStock(ctx, stockDeltas)
+}
+
+// dropDeletedProducts locks the products of stockDeltas in id order and
+// removes those that are deleted.
+func (u *OrderUsecase) dropDeletedProducts(ctx context.Context, stockDeltas map[int64]int) error {
+	productIDs := make([]int64, 0, len(stockDeltas))
+	for productID := range stockDeltas {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+	for _, productID := range productIDs {
+		_, err := u.productRepo.GetByIdForUpdate(ctx, productID)
+		if errors.Is(err, errorHelper.ErrorProductNotFound) {
+			delete(stockDeltas, productID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isOrderItemsError reports whether err is caused by the requested items
+// rather than by a failure of the service.
+func isOrderItemsError(err error) bool {
+	var validationErr *errorHelper.ValidationError
+
+	return errors.As(err, &validationErr) || errors.Is(err, errorHelper.ErrorProductNotFound) || errors.Is(err, errorHelper.ErrorInsufficientStock)
+}
+
+// ValidateCustomer checks the customer exists, is not deleted and is active.
+func (u *OrderUsecase) ValidateCustomer(ctx context.Context, customerID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	customer, err := u.repo.GetCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if !customer.IsActive {
+		return errorHelper.ErrorCustomerInactive
+	}
+
+	return nil
+}
+
+func (u *OrderUsecase) Transition(ctx context.Context, ID int64, request *orderDomainEntity.OrderTransitionRequest) (*orderDomainEntity.OrderResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if !isKnownOrderStatus(request.Status) {
+		return nil, errorHelper.ErrorOrderStatusInvalid
+	}
+
+	principal, err := principalAuth.Authenticated(ctx)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	order, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching order details")
+	}
+
+	if !canTransitionOrderStatus(order.Status, request.Status) {
+		return nil, errorHelper.ErrorOrderStatusConflict
 	}
 
-	// create order with order items
-	err := u.repo.Create(ctx, request)
+	history := &orderDomainEntity.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: order.Status,
+		ToStatus:   request.Status,
+		Reason:     request.Reason,
+		ChangedBy:  principal.UserID,
+	}
+
+	var result *orderDomainEntity.OrderResponse
+	err = u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := u.orderResponse(ctx, order)
+		if err != nil {
+			return err
+		}
+
+		if err := u.repo.UpdateStatus(ctx, order.ID, history.FromStatus, history.ToStatus); err != nil {
+			return err
+		}
+
+		if history.ToStatus == orderDomainEntity.OrderStatusCancelled && holdsStock(history.FromStatus) {
+			if err := u.releaseStock(ctx, order.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := u.repo.CreateStatusHistory(ctx, history); err != nil {
+			return err
+		}
+
+		result, err = u.GetByID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := u.audit.Record(ctx, auditDomainEntity.EntityOrder, order.ID, auditDomainEntity.ActionUpdate, before, result); err != nil {
+			return err
+		}
+
+		statusChanged := &orderDomainEntity.OrderStatusChangedEvent{
+			FromStatus: history.FromStatus,
+			ToStatus:   history.ToStatus,
+			Reason:     history.Reason,
+			ChangedBy:  history.ChangedBy,
+			Order:      result,
+		}
+
+		return u.outbox.Add(ctx, orderDomainEntity.EventAggregateOrder, order.ID, orderDomainEntity.EventOrderStatusChanged, statusChanged)
+	})
+	if errors.Is(err, errorHelper.ErrorOrderStatusConflict) {
+		return nil, err
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error inserting order")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error updating order status")
 	}
 
-	return request, nil
+	u.metrics.OrderStatusChanged(history.FromStatus, history.ToStatus)
+
+	return result, nil
 }
 
-func (u *OrderUsecase) ValidateCustomer(ctx context.Context, customerID int64) bool {
-	_, err := u.repo.GetCustomer(ctx, customerID)
+func (u *OrderUsecase) GetStatusHistory(ctx context.Context, ID int64) ([]orderDomainEntity.OrderStatusHistory, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	order, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching order details")
+	}
+
+	histories, err := u.repo.GetStatusHistory(ctx, order.ID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		return false
+		return nil, errorHelper.Wrap(err, "Error fetching order status history")
 	}
 
-	return true
+	return histories, nil
 }
@@ -0,0 +1,298 @@
+package orderUsecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
+	orderDomainInterface "github.com/ahsansandiah/dpo-test/api/order/domain"
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryOrders keeps a single order in memory, the methods the tests don't
+// use panic through the nil interface.
+type memoryOrders struct {
+	orderDomainInterface.OrderRepository
+
+	order *orderDomainEntity.Order
+	items []orderDomainEntity.OrderItem
+}
+
+func (m *memoryOrders) GetById(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	order := *m.order
+	return &order, nil
+}
+
+func (m *memoryOrders) GetByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	return m.GetById(ctx, ID)
+}
+
+func (m *memoryOrders) GetOrderCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error) {
+	return &customerDomainEntity.Customer{ID: customerID}, nil
+}
+
+func (m *memoryOrders) GetOrderItems(ctx context.Context, orderId int64) ([]orderDomainEntity.OrderItem, error) {
+	return m.items, nil
+}
+
+func (m *memoryOrders) UpdateStatus(ctx context.Context, ID int64, fromStatus string, toStatus string) error {
+	m.order.Status = toStatus
+	return nil
+}
+
+func (m *memoryOrders) CreateStatusHistory(ctx context.Context, history *orderDomainEntity.OrderStatusHistory) error {
+	return nil
+}
+
+func (m *memoryOrders) GetOpenByCustomerForUpdate(ctx context.Context, customerID int64) ([]orderDomainEntity.Order, error) {
+	return []orderDomainEntity.Order{*m.order}, nil
+}
+
+func (m *memoryOrders) CreateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error {
+	return nil
+}
+
+func (m *memoryOrders) UpdateItem(ctx context.Context, orderID int64, item *orderDomainEntity.OrderItem) error {
+	return nil
+}
+
+func (m *memoryOrders) UpdateTotals(ctx context.Context, order *orderDomainEntity.Order) error {
+	return nil
+}
+
+func (m *memoryOrders) GetDeletedByIdForUpdate(ctx context.Context, ID int64) (*orderDomainEntity.Order, error) {
+	return m.GetById(ctx, ID)
+}
+
+func (m *memoryOrders) GetDeletedByCustomerForUpdate(ctx context.Context, customerID int64, deletedAt time.Time) ([]orderDomainEntity.Order, error) {
+	return []orderDomainEntity.Order{*m.order}, nil
+}
+
+func (m *memoryOrders) GetCustomer(ctx context.Context, customerID int64) (*customerDomainEntity.Customer, error) {
+	return &customerDomainEntity.Customer{ID: customerID, IsActive: true}, nil
+}
+
+func (m *memoryOrders) Restore(ctx context.Context, ID int64) error {
+	m.order.DeletedAt = nil
+	return nil
+}
+
+func (m *memoryOrders) Delete(ctx context.Context, ID int64, deletedAt time.Time) error {
+	m.order.DeletedAt = &deletedAt
+	return nil
+}
+
+// memoryProducts records the locked products and the stock adjustments per
+// product, the deleted products are not found.
+type memoryProducts struct {
+	productDomainInterface.ProductRepository
+
+	deleted  map[int64]bool
+	locked   []int64
+	adjusted map[int64]int
+}
+
+func (m *memoryProducts) GetByIdForUpdate(ctx context.Context, ID int64) (*productDomainEntity.Product, error) {
+	if ID == 0 || m.deleted[ID] {
+		return nil, errorHelper.ErrorProductNotFound
+	}
+
+	m.locked = append(m.locked, ID)
+	return &productDomainEntity.Product{ID: ID, Stock: 100}, nil
+}
+
+func (m *memoryProducts) AdjustStock(ctx context.Context, ID int64, delta int) error {
+	m.adjusted[ID] += delta
+	return nil
+}
+
+type directTransaction struct {
+	transactionDatabase.Transaction
+}
+
+func (directTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// recordingAudit keeps the actions recorded per entity.
+type recordingAudit struct {
+	auditDomainInterface.AuditUsecase
+
+	actions map[int64][]string
+}
+
+func (r *recordingAudit) Record(ctx context.Context, entityType string, entityID int64, action string, before, after interface{}) error {
+	r.actions[entityID] = append(r.actions[entityID], action)
+	return nil
+}
+
+// recordingOutbox keeps the event types added per aggregate.
+type recordingOutbox struct {
+	outbox.Outbox
+
+	events map[int64][]string
+}
+
+func (r *recordingOutbox) Add(ctx context.Context, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	r.events[aggregateID] = append(r.events[aggregateID], eventType)
+	return nil
+}
+
+type discardMetrics struct {
+	metrics.Metrics
+}
+
+func (discardMetrics) OrderStatusChanged(from, to string) {}
+
+func newTestUsecase(status string) (*OrderUsecase, *memoryOrders, *memoryProducts) {
+	cfg := &config.Config{LogLevel: "panic"}
+	orders := &memoryOrders{
+		order: &orderDomainEntity.Order{ID: 7, CustomerID: 3, Status: status},
+		items: []orderDomainEntity.OrderItem{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 2, Quantity: 5},
+			{ProductID: 1, Quantity: 1},
+		},
+	}
+	products := &memoryProducts{deleted: map[int64]bool{}, adjusted: map[int64]int{}}
+
+	usecase := &OrderUsecase{
+		log:         logger.NewLog(cfg),
+		cfg:         cfg,
+		trx:         directTransaction{},
+		repo:        orders,
+		productRepo: products,
+		audit:       &recordingAudit{actions: map[int64][]string{}},
+		outbox:      &recordingOutbox{events: map[int64][]string{}},
+		metrics:     discardMetrics{},
+	}
+
+	return usecase, orders, products
+}
+
+func TestCancelGivesStockBack(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusConfirmed)
+	ctx := principalAuth.NewContext(context.Background(), &principalAuth.Principal{UserID: 1})
+
+	_, err := usecase.Transition(ctx, 7, &orderDomainEntity.OrderTransitionRequest{Status: orderDomainEntity.OrderStatusCancelled})
+
+	assert.NoError(t, err)
+	assert.Equal(t, orderDomainEntity.OrderStatusCancelled, orders.order.Status)
+	assert.Equal(t, map[int64]int{1: 3, 2: 5}, products.adjusted)
+}
+
+func TestTransitionKeepsStockReserved(t *testing.T) {
+	usecase, _, products := newTestUsecase(orderDomainEntity.OrderStatusConfirmed)
+	ctx := principalAuth.NewContext(context.Background(), &principalAuth.Principal{UserID: 1})
+
+	_, err := usecase.Transition(ctx, 7, &orderDomainEntity.OrderTransitionRequest{Status: orderDomainEntity.OrderStatusProcessing})
+
+	assert.NoError(t, err)
+	assert.Empty(t, products.adjusted)
+}
+
+func TestDeleteGivesStockBack(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusPending)
+
+	err := usecase.Delete(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, orders.order.DeletedAt)
+	assert.Equal(t, map[int64]int{1: 3, 2: 5}, products.adjusted)
+}
+
+func TestDeleteOfShippedOrderKeepsStock(t *testing.T) {
+	usecase, _, products := newTestUsecase(orderDomainEntity.OrderStatusShipped)
+
+	err := usecase.Delete(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.Empty(t, products.adjusted)
+}
+
+func TestDeleteOfCustomerDeletesOpenOrders(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusProcessing)
+	deletedAt := time.Now()
+
+	err := usecase.DeleteOfCustomer(context.Background(), 3, deletedAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &deletedAt, orders.order.DeletedAt)
+	assert.Equal(t, map[int64]int{1: 3, 2: 5}, products.adjusted)
+	assert.Equal(t, []string{orderDomainEntity.EventOrderDeleted}, usecase.outbox.(*recordingOutbox).events[7])
+	assert.Equal(t, []string{auditDomainEntity.ActionDelete}, usecase.audit.(*recordingAudit).actions[7])
+}
+
+func TestRestoreTakesStockOfLiveProductsOnly(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusConfirmed)
+	orders.items = append(orders.items, orderDomainEntity.OrderItem{ProductID: 0, Quantity: 4}, orderDomainEntity.OrderItem{ProductID: 9, Quantity: 6})
+	products.deleted[9] = true
+
+	_, err := usecase.Restore(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.Nil(t, orders.order.DeletedAt)
+	assert.Equal(t, map[int64]int{1: -3, 2: -5}, products.adjusted)
+}
+
+func TestRestoreOfCustomerSkipsLegacyItems(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusPending)
+	orders.items = []orderDomainEntity.OrderItem{{ProductID: 0, Quantity: 4}}
+
+	err := usecase.RestoreOfCustomer(context.Background(), 3, time.Now())
+
+	assert.NoError(t, err)
+	assert.Empty(t, products.adjusted)
+	assert.Equal(t, []string{orderDomainEntity.EventOrderRestored}, usecase.outbox.(*recordingOutbox).events[7])
+}
+
+func TestLockProductsLocksEveryProductOnceInIdOrder(t *testing.T) {
+	usecase, _, products := newTestUsecase(orderDomainEntity.OrderStatusPending)
+
+	locked, err := usecase.lockProducts(context.Background(), []int64{9, 2, 9, 5, 2})
+
+	assert.NoError(t, err)
+	assert.Len(t, locked, 3)
+	assert.Equal(t, []int64{2, 5, 9}, products.locked)
+}
+
+func TestUpdateItemsLeavesLegacyAndDeletedProductsAlone(t *testing.T) {
+	usecase, orders, products := newTestUsecase(orderDomainEntity.OrderStatusPending)
+	orders.items = []orderDomainEntity.OrderItem{
+		{ID: 1, ProductID: 0, Quantity: 1},
+		{ID: 2, ProductID: 5, Quantity: 1},
+		{ID: 3, ProductID: 1, Quantity: 2},
+	}
+	products.deleted[5] = true
+
+	_, err := usecase.UpdateItems(context.Background(), 7, &orderDomainEntity.OrderItemsPatchRequest{
+		Add:    []orderDomainEntity.OrderItemRequest{{ProductID: 2, Quantity: 1}},
+		Update: []orderDomainEntity.OrderItemUpdateRequest{{ID: 3, Quantity: 4}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, products.locked[:2])
+	assert.Equal(t, map[int64]int{1: -2, 2: -1}, products.adjusted)
+}
+
+func TestGetAllRejectsBadCursor(t *testing.T) {
+	usecase, _, _ := newTestUsecase(orderDomainEntity.OrderStatusPending)
+
+	_, err := usecase.GetAll(context.Background(), &orderDomainEntity.OrderFilter{Cursor: "not-a-cursor"})
+
+	assert.ErrorIs(t, err, errorHelper.ErrorInvalidCursor)
+	assert.Equal(t, errorHelper.KindBadRequest, errorHelper.KindOf(err))
+}
@@ -0,0 +1,174 @@
+package productHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	productUsecase "github.com/ahsansandiah/dpo-test/api/product/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	res "github.com/ahsansandiah/dpo-test/packages/json"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+type Product struct {
+	log     log.Log
+	Json    res.Json
+	Usecase productDomainInterface.ProductUsecase
+}
+
+func NewProductHandler(mgr manager.Manager) productDomainInterface.ProductHandler {
+	handler := new(Product)
+	handler.Usecase = productUsecase.NewProductUsecase(mgr)
+	handler.Json = mgr.GetJson()
+
+	return handler
+}
+
+func (h *Product) GetAll() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		queryParams := r.URL.Query()
+		limitStr := queryParams.Get("limit")
+		limit := paginateHelper.DefaultLimit
+		if limitStr != "" {
+			l, err := strconv.Atoi(limitStr)
+			if err == nil {
+				limit = l
+			}
+		}
+
+		filter := &productDomainEntity.ProductFilter{
+			SKU:    queryParams.Get("sku"),
+			Name:   queryParams.Get("name"),
+			LIMIT:  limit,
+			Cursor: queryParams.Get("cursor"),
+			SortBy: queryParams.Get("sort_by"),
+		}
+
+		result, err := h.Usecase.GetAll(ctx, filter)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.PaginateResponse(w, r, "Success get data", result.Product, result.Paginate)
+	})
+}
+
+func (h *Product) Delete() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		productIDStr := mux.Vars(r)["id"]
+		productID, err := strconv.ParseInt(productIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		err = h.Usecase.Delete(ctx, productID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, fmt.Sprintf("Product with ID %d deleted successfully", productID), nil)
+	})
+}
+
+func (h *Product) GetByID() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		productIDStr := mux.Vars(r)["id"]
+		productID, err := strconv.ParseInt(productIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		product, err := h.Usecase.GetByID(ctx, productID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success get data", product)
+	})
+}
+
+func (h *Product) Update() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		productIDStr := mux.Vars(r)["id"]
+		productID, err := strconv.ParseInt(productIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		var req *productDomainEntity.ProductRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		product, err := h.Usecase.Update(ctx, productID, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success updated", product)
+	})
+}
+
+func (h *Product) Create() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		var req *productDomainEntity.ProductRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		product, err := h.Usecase.Create(ctx, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.CreatedResponse(w, r, "Success created", product)
+	})
+}
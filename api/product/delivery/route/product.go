@@ -0,0 +1,19 @@
+package productRoute
+
+import (
+	productHandler "github.com/ahsansandiah/dpo-test/api/product/delivery/handler"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewProductRoute(mgr manager.Manager, route *mux.Router) {
+	productHandler := productHandler.NewProductHandler(mgr)
+	can := mgr.GetMiddleware().RequirePermission
+
+	route.Handle("/products", can(productDomainEntity.PermissionProductRead)(productHandler.GetAll())).Methods("GET")
+	route.Handle("/products/{id}", can(productDomainEntity.PermissionProductDelete)(productHandler.Delete())).Methods("DELETE")
+	route.Handle("/products/{id}", can(productDomainEntity.PermissionProductRead)(productHandler.GetByID())).Methods("GET")
+	route.Handle("/products/{id}", can(productDomainEntity.PermissionProductWrite)(productHandler.Update())).Methods("PUT")
+	route.Handle("/products", can(productDomainEntity.PermissionProductWrite)(productHandler.Create())).Methods("POST")
+}
@@ -0,0 +1,14 @@
+package productRoutes
+
+import (
+	productRoute "github.com/ahsansandiah/dpo-test/api/product/delivery/route"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewRoutes(r *mux.Router, mgr manager.Manager) {
+	apiAuth := r.PathPrefix("").Subrouter()
+	apiAuth.Use(mgr.GetMiddleware().CheckToken, mgr.GetMiddleware().RateLimit("api"), mgr.GetMiddleware().Idempotency)
+
+	productRoute.NewProductRoute(mgr, apiAuth)
+}
@@ -0,0 +1,68 @@
+package productDomainEntity
+
+import (
+	"time"
+
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PermissionProductRead   = "products:read"
+	PermissionProductWrite  = "products:write"
+	PermissionProductDelete = "products:delete"
+)
+
+type Product struct {
+	ID          int64           `json:"id"`
+	SKU         string          `json:"sku"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Stock       int             `json:"stock"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type ProductRequest struct {
+	SKU         string          `json:"sku"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	Stock       *int            `json:"stock"`
+}
+
+type ProductListResponse struct {
+	Product  []Product                `json:"product"`
+	Paginate *paginateHelper.Paginate `json:"paginate"`
+}
+
+type ProductFilter struct {
+	SKU    string                 `json:"sku"`
+	Name   string                 `json:"name"`
+	LIMIT  int                    `json:"limit"`
+	Cursor string                 `json:"cursor"`
+	SortBy string                 `json:"sort_by"`
+	Keyset *paginateHelper.Cursor `json:"-"`
+}
+
+func (r *ProductRequest) Validate() error {
+	v := validationHelper.New()
+
+	if v.Required("sku", r.SKU) {
+		v.Length("sku", r.SKU, 1, 64)
+	}
+
+	if v.Required("name", r.Name) {
+		v.Length("name", r.Name, 1, 255)
+	}
+
+	v.Check(r.UnitPrice.IsPositive(), "unit_price", validationHelper.RuleMin, "unit_price must be greater than zero")
+
+	if r.Stock != nil {
+		v.Min("stock", int64(*r.Stock), 0)
+	}
+
+	return v.Err()
+}
@@ -0,0 +1,36 @@
+package productDomainInterface
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+)
+
+type ProductHandler interface {
+	GetAll() http.Handler
+	Delete() http.Handler
+	GetByID() http.Handler
+	Update() http.Handler
+	Create() http.Handler
+}
+
+type ProductUsecase interface {
+	GetAll(ctx context.Context, filter *productDomainEntity.ProductFilter) (*productDomainEntity.ProductListResponse, error)
+	Delete(ctx context.Context, ID int64) error
+	GetByID(ctx context.Context, ID int64) (*productDomainEntity.Product, error)
+	Update(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error)
+	Create(ctx context.Context, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error)
+}
+
+type ProductRepository interface {
+	GetAll(ctx context.Context, filter *productDomainEntity.ProductFilter) ([]productDomainEntity.Product, error)
+	GetById(ctx context.Context, ID int64) (*productDomainEntity.Product, error)
+	GetByIdForUpdate(ctx context.Context, ID int64) (*productDomainEntity.Product, error)
+	AdjustStock(ctx context.Context, ID int64, delta int) error
+	Delete(ctx context.Context, ID int64) error
+	Update(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error)
+	Create(ctx context.Context, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
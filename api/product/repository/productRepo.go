@@ -0,0 +1,229 @@
+package productRepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlErrDuplicateEntry is the MySQL error number of unique key violations.
+const mysqlErrDuplicateEntry = 1062
+
+type Product struct {
+	DB  transactionDatabase.Transaction
+	log log.Log
+	cfg *config.Config
+}
+
+func NewProductRepository(mgr manager.Manager) productDomainInterface.ProductRepository {
+	repo := new(Product)
+	repo.DB = mgr.GetTransaction()
+	repo.log = mgr.GetLog()
+	repo.cfg = mgr.GetConfig()
+
+	return repo
+}
+
+func (r *Product) GetAll(ctx context.Context, filter *productDomainEntity.ProductFilter) ([]productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT p.id, p.sku, p.name, p.description, p.unit_price, p.stock, p.created_at, p.updated_at FROM products p WHERE p.deleted_at IS NULL"
+	var args []interface{}
+
+	if filter.SKU != "" {
+		query += " AND p.sku = ?"
+		args = append(args, filter.SKU)
+	}
+
+	if filter.Name != "" {
+		query += " AND p.name = ?"
+		args = append(args, filter.Name)
+	}
+
+	backward := false
+	if filter.Keyset != nil {
+		condition, keysetArgs := filter.Keyset.Condition("p")
+		query += condition
+		args = append(args, keysetArgs...)
+		backward = filter.Keyset.Backward
+	}
+
+	query += paginateHelper.OrderBy("p", filter.SortBy, backward)
+	query += " LIMIT ?"
+	args = append(args, filter.LIMIT)
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []productDomainEntity.Product{}
+	for rows.Next() {
+		var product productDomainEntity.Product
+		if err := rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.UnitPrice, &product.Stock, &product.CreatedAt, &product.UpdatedAt); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *Product) GetById(ctx context.Context, ID int64) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	product := productDomainEntity.Product{}
+
+	query := "SELECT id, sku, name, description, unit_price, stock, created_at, updated_at FROM products WHERE id = ? AND deleted_at IS NULL"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.UnitPrice, &product.Stock, &product.CreatedAt, &product.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorProductNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// GetByIdForUpdate reads a product and, inside a transaction, locks its row
+// until the transaction ends.
+func (r *Product) GetByIdForUpdate(ctx context.Context, ID int64) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	product := productDomainEntity.Product{}
+
+	query := "SELECT id, sku, name, description, unit_price, stock, created_at, updated_at FROM products WHERE id = ? AND deleted_at IS NULL FOR UPDATE"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.UnitPrice, &product.Stock, &product.CreatedAt, &product.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorProductNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// AdjustStock adds delta, which may be negative, to the stock of a product.
+func (r *Product) AdjustStock(ctx context.Context, ID int64, delta int) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE products SET stock = stock + ? WHERE id = ?", delta, ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Product) Delete(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	// Set deleted_at to current timestamp
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE products SET deleted_at = ? WHERE id = ?", time.Now(), ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *Product) Update(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE products SET sku = ?, name = ?, description = ?, unit_price = ?, stock = ? WHERE id = ?",
+		request.SKU, request.Name, request.Description, request.UnitPrice, request.Stock, ID)
+	if isDuplicateEntry(err) {
+		return nil, errorHelper.ErrorSkuAlreadyExists
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return r.GetById(ctx, ID)
+}
+
+func (r *Product) Create(ctx context.Context, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO products (sku, name, description, unit_price, stock) VALUES (?, ?, ?, ?, ?)",
+		request.SKU, request.Name, request.Description, request.UnitPrice, request.Stock)
+	if isDuplicateEntry(err) {
+		return nil, errorHelper.ErrorSkuAlreadyExists
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	// Get ID of the inserted record
+	productID, err := result.LastInsertId()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return r.GetById(ctx, productID)
+}
+
+// Purge removes the products deleted before the given time for good. Products that
+// are still on an order are kept.
+func (r *Product) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `DELETE FROM products WHERE deleted_at < ? AND NOT EXISTS (
+                SELECT 1 FROM order_items oi WHERE oi.product_id = products.id
+              )`
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
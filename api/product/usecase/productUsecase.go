@@ -0,0 +1,184 @@
+package productUsecase
+
+import (
+	"context"
+	"errors"
+
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	productRepository "github.com/ahsansandiah/dpo-test/api/product/repository"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+)
+
+type ProductUsecase struct {
+	log  log.Log
+	cfg  *config.Config
+	repo productDomainInterface.ProductRepository
+	trx  transactionDatabase.Transaction
+}
+
+func NewProductUsecase(mgr manager.Manager) productDomainInterface.ProductUsecase {
+	usecase := new(ProductUsecase)
+	usecase.log = mgr.GetLog()
+	usecase.cfg = mgr.GetConfig()
+	usecase.repo = productRepository.NewProductRepository(mgr)
+	usecase.trx = mgr.GetTransaction()
+
+	return usecase
+}
+
+func (u *ProductUsecase) GetAll(ctx context.Context, filter *productDomainEntity.ProductFilter) (*productDomainEntity.ProductListResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	sortBy, err := paginateHelper.ValidateSortBy(filter.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	filter.SortBy = sortBy
+	filter.LIMIT = paginateHelper.ValidateLimit(filter.LIMIT)
+
+	if filter.Cursor != "" {
+		cursor, err := paginateHelper.ParseCursor(filter.Cursor, u.cfg.PaginateCursorSecret)
+		if err != nil {
+			return nil, err
+		}
+		filter.Keyset = cursor
+		filter.SortBy = cursor.SortBy
+	}
+
+	// fetch one extra product to know whether another page exists
+	limit := filter.LIMIT
+	filter.LIMIT = limit + 1
+	products, err := u.repo.GetAll(ctx, filter)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	filter.LIMIT = limit
+
+	hasMore := len(products) > limit
+	if hasMore {
+		products = products[:limit]
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+
+	var first, last *paginateHelper.Cursor
+	if len(products) > 0 {
+		firstCursor := paginateHelper.NewCursor(filter.SortBy, products[0].CreatedAt, products[0].ID)
+		lastCursor := paginateHelper.NewCursor(filter.SortBy, products[len(products)-1].CreatedAt, products[len(products)-1].ID)
+		first, last = &firstCursor, &lastCursor
+	}
+
+	paginate, err := paginateHelper.NewPaginate(u.cfg.PaginateCursorSecret, filter.Keyset, hasMore, first, last)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	result := &productDomainEntity.ProductListResponse{
+		Product:  products,
+		Paginate: paginate,
+	}
+
+	return result, nil
+}
+
+func (u *ProductUsecase) Delete(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Error fetching product details")
+	}
+
+	err = u.repo.Delete(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Error deleting product")
+	}
+
+	return nil
+}
+
+func (u *ProductUsecase) GetByID(ctx context.Context, ID int64) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	product, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching product details")
+	}
+
+	return product, nil
+}
+
+func (u *ProductUsecase) Update(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	// the row stays locked until the update commits, so an order reserving
+	// stock in between waits instead of being overwritten
+	var result *productDomainEntity.Product
+	err := u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		product, err := u.repo.GetByIdForUpdate(ctx, ID)
+		if err != nil {
+			return err
+		}
+
+		if request.Stock == nil {
+			request.Stock = &product.Stock
+		}
+
+		if *request.Stock < 0 {
+			return errorHelper.ErrorStockIsInvalid
+		}
+
+		result, err = u.repo.Update(ctx, ID, request)
+		return err
+	})
+	if errors.Is(err, errorHelper.ErrorSkuAlreadyExists) || errors.Is(err, errorHelper.ErrorStockIsInvalid) {
+		return nil, err
+	}
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error updating product")
+	}
+
+	return result, nil
+}
+
+func (u *ProductUsecase) Create(ctx context.Context, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if request.Stock == nil {
+		stock := 0
+		request.Stock = &stock
+	}
+
+	product, err := u.repo.Create(ctx, request)
+	if errors.Is(err, errorHelper.ErrorSkuAlreadyExists) {
+		return nil, err
+	}
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error inserting product")
+	}
+
+	return product, nil
+}
@@ -0,0 +1,119 @@
+package productUsecase
+
+import (
+	"context"
+	"testing"
+
+	productDomainInterface "github.com/ahsansandiah/dpo-test/api/product/domain"
+	productDomainEntity "github.com/ahsansandiah/dpo-test/api/product/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUsecase(t *testing.T) (*ProductUsecase, *productDomainInterface.MockProductRepository) {
+	ctrl := gomock.NewController(t)
+	repo := productDomainInterface.NewMockProductRepository(ctrl)
+
+	trx := transactionDatabase.NewMockTransaction(ctrl)
+	trx.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		}).AnyTimes()
+
+	usecase := &ProductUsecase{log: logger.NewLog(&config.Config{LogLevel: "panic"}), repo: repo, trx: trx}
+
+	return usecase, repo
+}
+
+func stock(n int) *int {
+	return &n
+}
+
+func TestCreateDefaultsStockToZero(t *testing.T) {
+	usecase, repo := newTestUsecase(t)
+
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+			assert.Equal(t, 0, *request.Stock)
+			return &productDomainEntity.Product{ID: 1, Stock: *request.Stock}, nil
+		})
+
+	product, err := usecase.Create(context.Background(), &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.NewFromInt(10)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, product.Stock)
+}
+
+func TestUpdateKeepsLockedStockWhenNoneIsGiven(t *testing.T) {
+	usecase, repo := newTestUsecase(t)
+
+	gomock.InOrder(
+		repo.EXPECT().GetByIdForUpdate(gomock.Any(), int64(1)).Return(&productDomainEntity.Product{ID: 1, Stock: 7, UnitPrice: decimal.NewFromInt(10)}, nil),
+		repo.EXPECT().Update(gomock.Any(), int64(1), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+				assert.Equal(t, 7, *request.Stock)
+				return &productDomainEntity.Product{ID: ID, Stock: *request.Stock, UnitPrice: request.UnitPrice}, nil
+			}),
+	)
+
+	product, err := usecase.Update(context.Background(), 1, &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.NewFromInt(12)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, product.Stock)
+}
+
+func TestUpdateStoresTheRequestedPrice(t *testing.T) {
+	usecase, repo := newTestUsecase(t)
+
+	repo.EXPECT().GetByIdForUpdate(gomock.Any(), int64(1)).Return(&productDomainEntity.Product{ID: 1, Stock: 7, UnitPrice: decimal.NewFromInt(10)}, nil)
+	repo.EXPECT().Update(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, ID int64, request *productDomainEntity.ProductRequest) (*productDomainEntity.Product, error) {
+			return &productDomainEntity.Product{ID: ID, Stock: *request.Stock, UnitPrice: request.UnitPrice}, nil
+		})
+
+	product, err := usecase.Update(context.Background(), 1, &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.RequireFromString("4.5"), Stock: stock(3)})
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("4.5").Equal(product.UnitPrice))
+	assert.Equal(t, 3, product.Stock)
+}
+
+func TestUpdateRejectsNegativeStock(t *testing.T) {
+	usecase, repo := newTestUsecase(t)
+
+	repo.EXPECT().GetByIdForUpdate(gomock.Any(), int64(1)).Return(&productDomainEntity.Product{ID: 1, Stock: 7}, nil)
+
+	_, err := usecase.Update(context.Background(), 1, &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.NewFromInt(10), Stock: stock(-1)})
+
+	assert.ErrorIs(t, err, errorHelper.ErrorStockIsInvalid)
+}
+
+func TestUpdateOfMissingProduct(t *testing.T) {
+	usecase, repo := newTestUsecase(t)
+
+	repo.EXPECT().GetByIdForUpdate(gomock.Any(), int64(1)).Return(nil, errorHelper.ErrorProductNotFound)
+
+	_, err := usecase.Update(context.Background(), 1, &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.NewFromInt(10)})
+
+	assert.ErrorIs(t, err, errorHelper.ErrorProductNotFound)
+}
+
+func TestProductRequestRejectsInvalidPriceAndStock(t *testing.T) {
+	request := &productDomainEntity.ProductRequest{SKU: "SKU-1", Name: "Kopi", UnitPrice: decimal.NewFromInt(10), Stock: stock(0)}
+	assert.NoError(t, request.Validate())
+
+	request.UnitPrice = decimal.NewFromInt(-1)
+	assert.Error(t, request.Validate())
+
+	request.UnitPrice = decimal.Zero
+	assert.Error(t, request.Validate())
+
+	request.UnitPrice = decimal.NewFromInt(10)
+	request.Stock = stock(-1)
+	assert.Error(t, request.Validate())
+}
@@ -7,96 +7,145 @@ import (
 	userDomainInterface "github.com/ahsansandiah/dpo-test/api/user/domain"
 	userDomainEntity "github.com/ahsansandiah/dpo-test/api/user/domain/entity"
 	userUsecase "github.com/ahsansandiah/dpo-test/api/user/usecase"
-	jwtAuth "github.com/ahsansandiah/dpo-test/packages/auth/jwt"
-	middlewareAuth "github.com/ahsansandiah/dpo-test/packages/auth/middleware"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	res "github.com/ahsansandiah/dpo-test/packages/json"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
 )
 
 type User struct {
-	log        log.Log
-	Json       res.Json
-	Usecase    userDomainInterface.UserUsecase
-	jwt        jwtAuth.Jwt
-	Middleware middlewareAuth.Middleware
+	log     log.Log
+	Json    res.Json
+	Usecase userDomainInterface.UserUsecase
 }
 
 func NewUserHandler(mgr manager.Manager) userDomainInterface.UserHandler {
 	handler := new(User)
 	handler.Usecase = userUsecase.NewUserUsecase(mgr)
 	handler.Json = mgr.GetJson()
-	handler.Middleware = mgr.GetMiddleware()
 
 	return handler
 }
 
 func (h *User) Detail() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
-		token, err := h.Middleware.GetTokenInHeader(r)
+		customer, err := h.Usecase.GetUserLogin(ctx)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		customer, err := h.Usecase.GetUserLogin(ctx, token)
-		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
-			return
-		}
-
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success get data", customer)
+		h.Json.SuccessResponse(w, r, "Success get data", customer)
 	})
 }
 
 func (h *User) Create() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		var req *userDomainEntity.UserRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
 		err := h.Usecase.Create(ctx, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success created", nil)
+		h.Json.CreatedResponse(w, r, "Success created", nil)
 	})
 }
 
 func (h *User) Login() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
 
 		var req *userDomainEntity.LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
 			return
 		}
 
 		if err := req.LoginValidate(); err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusBadRequest, err)
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
 		result, err := h.Usecase.Login(ctx, req)
 		if err != nil {
-			h.Json.ErrorResponse(w, r, http.StatusInternalServerError, err)
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success login", result)
+	})
+}
+
+func (h *User) Refresh() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		var req *userDomainEntity.RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		result, err := h.Usecase.Refresh(ctx, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success refreshed", result)
+	})
+}
+
+func (h *User) Logout() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		// the refresh token is optional, so an empty body is accepted
+		req := &userDomainEntity.LogoutRequest{}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+				return
+			}
+		}
+
+		err := h.Usecase.Logout(ctx, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
 			return
 		}
 
-		h.Json.SuccessResponse(w, r, http.StatusCreated, "Success created", result)
+		h.Json.SuccessResponse(w, r, "Success logged out", nil)
 	})
 }
@@ -6,13 +6,15 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewUserRoute(mgr manager.Manager, route *mux.Router) {
+func NewUserRoute(mgr manager.Manager, route *mux.Router, routeAuth *mux.Router) {
 	userHandler := userHandler.NewUserHandler(mgr)
 
 	// user
-	route.Handle("/users", userHandler.Detail()).Methods("GET")
+	routeAuth.Handle("/users", userHandler.Detail()).Methods("GET")
 	route.Handle("/users", userHandler.Create()).Methods("POST")
 
 	// authentication
 	route.Handle("/auth/login", userHandler.Login()).Methods("POST")
+	route.Handle("/auth/refresh", userHandler.Refresh()).Methods("POST")
+	routeAuth.Handle("/auth/logout", userHandler.Logout()).Methods("POST")
 }
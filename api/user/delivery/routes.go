@@ -8,6 +8,10 @@ import (
 
 func NewRoutes(r *mux.Router, mgr manager.Manager) {
 	api := r.PathPrefix("").Subrouter()
+	api.Use(mgr.GetMiddleware().RateLimit("auth"))
 
-	userRoute.NewUserRoute(mgr, api)
+	apiAuth := r.PathPrefix("").Subrouter()
+	apiAuth.Use(mgr.GetMiddleware().CheckToken, mgr.GetMiddleware().RateLimit("api"))
+
+	userRoute.NewUserRoute(mgr, api, apiAuth)
 }
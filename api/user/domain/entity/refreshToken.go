@@ -0,0 +1,32 @@
+package userDomainEntity
+
+import (
+	"time"
+
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+)
+
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *RefreshRequest) Validate() error {
+	v := validationHelper.New()
+	v.Required("refresh_token", r.RefreshToken)
+
+	return v.Err()
+}
@@ -3,9 +3,12 @@ package userDomainEntity
 import (
 	"time"
 
-	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
 )
 
+// DefaultRole is granted to every newly registered user.
+const DefaultRole = "viewer"
+
 type User struct {
 	ID           int       `json:"id"`
 	Username     string    `json:"username"`
@@ -16,11 +19,13 @@ type User struct {
 }
 
 type UserResponse struct {
-	ID        int       `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int       `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type UserRequest struct {
@@ -37,43 +42,39 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token        string     `json:"token"`
-	RefreshToken string     `json:"refresh_token"`
-	ExpiryTime   *time.Time `json:"expiry_time"`
+	Token                  string     `json:"token"`
+	RefreshToken           string     `json:"refresh_token"`
+	ExpiryTime             *time.Time `json:"expiry_time"`
+	RefreshTokenExpiryTime *time.Time `json:"refresh_token_expiry_time"`
 }
 
 func (r *UserRequest) Validate() error {
-	if r.Username == "" {
-		return errorHelper.ErrorUsernameIsRequired
-	}
+	v := validationHelper.New()
 
-	if r.Password == "" {
-		return errorHelper.ErrorPasswordIsRequired
+	if v.Required("username", r.Username) {
+		v.Length("username", r.Username, 3, 50)
 	}
 
-	if r.PasswordConfirm == "" {
-		return errorHelper.ErrorPasswordConfirmIsRequired
+	if v.Required("password", r.Password) {
+		v.Password("password", r.Password)
 	}
 
-	if r.Password != r.PasswordConfirm {
-		return errorHelper.ErrorPasswordNotMatch
+	if v.Required("password_confirm", r.PasswordConfirm) {
+		v.Match("password_confirm", r.PasswordConfirm, "password", r.Password)
 	}
 
-	if r.Email == "" {
-		return errorHelper.ErrorEmailIsRequired
+	if v.Required("email", r.Email) {
+		v.Length("email", r.Email, 1, 255)
+		v.Email("email", r.Email)
 	}
 
-	return nil
+	return v.Err()
 }
 
 func (r *LoginRequest) LoginValidate() error {
-	if r.Username == "" {
-		return errorHelper.ErrorUsernameIsRequired
-	}
-
-	if r.Password == "" {
-		return errorHelper.ErrorPasswordIsRequired
-	}
+	v := validationHelper.New()
+	v.Required("username", r.Username)
+	v.Required("password", r.Password)
 
-	return nil
+	return v.Err()
 }
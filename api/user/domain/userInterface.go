@@ -3,6 +3,7 @@ package userDomainInterface
 import (
 	"context"
 	"net/http"
+	"time"
 
 	userDomainEntity "github.com/ahsansandiah/dpo-test/api/user/domain/entity"
 )
@@ -11,16 +12,27 @@ type UserHandler interface {
 	Detail() http.Handler
 	Create() http.Handler
 	Login() http.Handler
+	Refresh() http.Handler
+	Logout() http.Handler
 }
 
 type UserUsecase interface {
-	GetUserLogin(ctx context.Context, token string) (*userDomainEntity.UserResponse, error)
+	GetUserLogin(ctx context.Context) (*userDomainEntity.UserResponse, error)
 	Create(ctx context.Context, request *userDomainEntity.UserRequest) error
 	Login(ctx context.Context, request *userDomainEntity.LoginRequest) (*userDomainEntity.LoginResponse, error)
+	Refresh(ctx context.Context, request *userDomainEntity.RefreshRequest) (*userDomainEntity.LoginResponse, error)
+	Logout(ctx context.Context, request *userDomainEntity.LogoutRequest) error
 }
 
 type UserRepository interface {
 	GetById(ctx context.Context, ID int64) (*userDomainEntity.User, error)
-	Create(ctx context.Context, request *userDomainEntity.UserRequest) error
+	Create(ctx context.Context, request *userDomainEntity.UserRequest) (int64, error)
 	GetByUsername(ctx context.Context, username string) (*userDomainEntity.User, error)
+	CreateRefreshToken(ctx context.Context, token *userDomainEntity.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*userDomainEntity.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, ID int64) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	GetRoles(ctx context.Context, userID int64) ([]string, error)
+	GetPermissions(ctx context.Context, userID int64) ([]string, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
@@ -2,24 +2,26 @@ package userRepository
 
 import (
 	"context"
-	"database/sql"
+	"time"
 
 	userDomainInterface "github.com/ahsansandiah/dpo-test/api/user/domain"
 	userDomainEntity "github.com/ahsansandiah/dpo-test/api/user/domain/entity"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
 )
 
 type User struct {
-	DB  *sql.DB
+	DB  transactionDatabase.Transaction
 	log log.Log
 	cfg *config.Config
 }
 
 func NewUserRepository(mgr manager.Manager) userDomainInterface.UserRepository {
 	repo := new(User)
-	repo.DB = mgr.GetDB()
+	repo.DB = mgr.GetTransaction()
 	repo.log = mgr.GetLog()
 	repo.cfg = mgr.GetConfig()
 
@@ -27,10 +29,60 @@ func NewUserRepository(mgr manager.Manager) userDomainInterface.UserRepository {
 }
 
 func (r *User) GetById(ctx context.Context, ID int64) (*userDomainEntity.User, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	user := userDomainEntity.User{}
+
+	query := "SELECT id, username, email, created_at, updated_at FROM users WHERE id = ? AND deleted_at IS NULL"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *User) Create(ctx context.Context, request *userDomainEntity.UserRequest) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	var userID int64
+	err := r.DB.WithinTransaction(ctx, func(ctx context.Context) error {
+		result, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO users (username, password_hash, email) VALUES (?, ?, ?)", request.Username, request.PasswordHash, request.Email)
+		if err != nil {
+			r.log.ErrorLog(ctx, err)
+			return err
+		}
+
+		userID, err = result.LastInsertId()
+		if err != nil {
+			r.log.ErrorLog(ctx, err)
+			return err
+		}
+
+		// new users start with the least privileged role
+		_, err = r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO user_roles (user_id, role_id) SELECT ?, id FROM roles WHERE name = ?", userID, userDomainEntity.DefaultRole)
+		if err != nil {
+			r.log.ErrorLog(ctx, err)
+			return err
+		}
+
+		return nil
+	})
+
+	return userID, err
+}
+
+func (r *User) GetByUsername(ctx context.Context, username string) (*userDomainEntity.User, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	user := userDomainEntity.User{}
 
-	query := "SELECT id, username, email, created_at, updated_at FROM users WHERE id = ?"
-	err := r.DB.QueryRow(query, ID).Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	query := "SELECT id, username, email, password_hash, created_at, updated_at FROM users WHERE username = ? AND deleted_at IS NULL"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
@@ -39,8 +91,11 @@ func (r *User) GetById(ctx context.Context, ID int64) (*userDomainEntity.User, e
 	return &user, nil
 }
 
-func (r *User) Create(ctx context.Context, request *userDomainEntity.UserRequest) error {
-	_, err := r.DB.ExecContext(ctx, "INSERT INTO users (username, password_hash, email) VALUES (?, ?, ?)", request.Username, request.PasswordHash, request.Email)
+func (r *User) CreateRefreshToken(ctx context.Context, token *userDomainEntity.RefreshToken) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?)", token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return err
@@ -49,15 +104,128 @@ func (r *User) Create(ctx context.Context, request *userDomainEntity.UserRequest
 	return nil
 }
 
-func (r *User) GetByUsername(ctx context.Context, username string) (*userDomainEntity.User, error) {
-	user := userDomainEntity.User{}
+func (r *User) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*userDomainEntity.RefreshToken, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	token := userDomainEntity.RefreshToken{}
 
-	query := "SELECT id, username, email, password_hash, created_at, updated_at FROM users WHERE username = ?"
-	err := r.DB.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	query := "SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = ?"
+	err := r.DB.Executor(ctx).QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt)
 	if err != nil {
 		r.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 
-	return &user, nil
+	return &token, nil
+}
+
+// RevokeRefreshToken marks a refresh token as used. It reports false when the
+// token had already been revoked, e.g. by a concurrent refresh.
+func (r *User) RevokeRefreshToken(ctx context.Context, ID int64) (bool, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now(), ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *User) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", time.Now(), familyID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *User) GetRoles(ctx context.Context, userID int64) ([]string, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `SELECT r.name
+              FROM roles r
+              INNER JOIN user_roles ur ON ur.role_id = r.id
+              WHERE ur.user_id = ?
+              ORDER BY r.name`
+
+	return r.queryNames(ctx, query, userID)
+}
+
+func (r *User) GetPermissions(ctx context.Context, userID int64) ([]string, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `SELECT DISTINCT p.name
+              FROM permissions p
+              INNER JOIN role_permissions rp ON rp.permission_id = p.id
+              INNER JOIN user_roles ur ON ur.role_id = rp.role_id
+              WHERE ur.user_id = ?
+              ORDER BY p.name`
+
+	return r.queryNames(ctx, query, userID)
+}
+
+func (r *User) queryNames(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// Purge removes the users deleted before the given time for good. Users that
+// changed the status of an order are kept for the order history.
+func (r *User) Purge(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `DELETE FROM users WHERE deleted_at < ? AND NOT EXISTS (
+                SELECT 1 FROM order_status_history h WHERE h.changed_by = users.id
+              )`
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, before)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return purged, nil
 }
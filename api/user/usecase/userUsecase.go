@@ -2,23 +2,37 @@ package userUsecase
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"time"
 
+	auditDomainInterface "github.com/ahsansandiah/dpo-test/api/audit/domain"
+	auditDomainEntity "github.com/ahsansandiah/dpo-test/api/audit/domain/entity"
+	auditUsecase "github.com/ahsansandiah/dpo-test/api/audit/usecase"
 	userDomainInterface "github.com/ahsansandiah/dpo-test/api/user/domain"
 	userDomainEntity "github.com/ahsansandiah/dpo-test/api/user/domain/entity"
 	userRepository "github.com/ahsansandiah/dpo-test/api/user/repository"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	denylistAuth "github.com/ahsansandiah/dpo-test/packages/auth/denylist"
 	jwtAuth "github.com/ahsansandiah/dpo-test/packages/auth/jwt"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUsecase struct {
-	log  log.Log
-	cfg  *config.Config
-	repo userDomainInterface.UserRepository
-	jwt  jwtAuth.Jwt
+	log      log.Log
+	cfg      *config.Config
+	repo     userDomainInterface.UserRepository
+	jwt      jwtAuth.Jwt
+	denylist denylistAuth.Denylist
+	trx      transactionDatabase.Transaction
+	audit    auditDomainInterface.AuditUsecase
 }
 
 func NewUserUsecase(mgr manager.Manager) userDomainInterface.UserUsecase {
@@ -27,94 +41,260 @@ func NewUserUsecase(mgr manager.Manager) userDomainInterface.UserUsecase {
 	usecase.cfg = mgr.GetConfig()
 	usecase.repo = userRepository.NewUserRepository(mgr)
 	usecase.jwt = mgr.GetJwt()
+	usecase.denylist = mgr.GetDenylist()
+	usecase.trx = mgr.GetTransaction()
+	usecase.audit = auditUsecase.NewAuditUsecase(mgr)
 
 	return usecase
 }
 
-func (u *UserUsecase) GetUserLogin(ctx context.Context, token string) (*userDomainEntity.UserResponse, error) {
-	jwtData, err := u.jwt.ExtractJwtToken(token)
+func (u *UserUsecase) GetUserLogin(ctx context.Context) (*userDomainEntity.UserResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	principal, err := principalAuth.Authenticated(ctx)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Failed get user detail")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Failed get user detail")
 	}
 
-	customer, err := u.repo.GetById(ctx, jwtData.UserID)
+	customer, err := u.repo.GetById(ctx, principal.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorHelper.ErrorUserNotFound
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error fetching customer details")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Error fetching customer details")
 	}
 
 	result := &userDomainEntity.UserResponse{
-		ID:        customer.ID,
-		Username:  customer.Username,
-		Email:     customer.Email,
-		CreatedAt: customer.CreatedAt,
-		UpdatedAt: customer.UpdatedAt,
+		ID:          customer.ID,
+		Username:    customer.Username,
+		Email:       customer.Email,
+		Roles:       principal.Roles,
+		Permissions: principal.Permissions,
+		CreatedAt:   customer.CreatedAt,
+		UpdatedAt:   customer.UpdatedAt,
 	}
 
 	return result, nil
 }
 
 func (u *UserUsecase) Create(ctx context.Context, request *userDomainEntity.UserRequest) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error inserting user")
-		return errMsg
+		return errorHelper.Wrap(err, "Error inserting user")
 	}
 
 	request.PasswordHash = hashedPassword
-	err = u.repo.Create(ctx, request)
+	err = u.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		userID, err := u.repo.Create(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		user, err := u.repo.GetById(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		roles, err := u.repo.GetRoles(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// the response leaves the password hash out of the audit log
+		created := &userDomainEntity.UserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			Roles:     roles,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		}
+
+		return u.audit.Record(ctx, auditDomainEntity.EntityUser, userID, auditDomainEntity.ActionCreate, nil, created)
+	})
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Error inserting user")
-		return errMsg
+		return errorHelper.Wrap(err, "Error inserting user")
 	}
 
 	return nil
 }
 
 func (u *UserUsecase) Login(ctx context.Context, request *userDomainEntity.LoginRequest) (*userDomainEntity.LoginResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
 	// get user by username
 	user, err := u.repo.GetByUsername(ctx, request.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorHelper.ErrorInvalidCredentials
+	}
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Username does not match")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Failed to login")
 	}
 
 	// Example of verifying a hashed password
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password))
+	if err != nil {
+		return nil, errorHelper.ErrorInvalidCredentials
+	}
+
+	// every login starts a new refresh token family
+	return u.generateLoginResponse(ctx, user, uuid.New().String())
+}
+
+func (u *UserUsecase) Refresh(ctx context.Context, request *userDomainEntity.RefreshRequest) (*userDomainEntity.LoginResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	token, err := u.repo.GetRefreshTokenByHash(ctx, jwtAuth.HashToken(request.RefreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorHelper.ErrorRefreshTokenInvalid
+	}
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to refresh token")
+	}
+
+	// A refresh token is only valid once. Presenting a used one means it
+	// leaked, so the whole family issued since the last login is revoked.
+	if token.RevokedAt != nil {
+		return nil, u.revokeRefreshTokenFamily(ctx, token.FamilyID)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, errorHelper.ErrorRefreshTokenInvalid
+	}
+
+	rotated, err := u.repo.RevokeRefreshToken(ctx, token.ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to refresh token")
+	}
+
+	if !rotated {
+		return nil, u.revokeRefreshTokenFamily(ctx, token.FamilyID)
+	}
+
+	user, err := u.repo.GetById(ctx, token.UserID)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Password does not match")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Failed to refresh token")
+	}
+
+	return u.generateLoginResponse(ctx, user, token.FamilyID)
+}
+
+func (u *UserUsecase) Logout(ctx context.Context, request *userDomainEntity.LogoutRequest) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	principal, err := principalAuth.Authenticated(ctx)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Failed to logout")
+	}
+
+	err = u.denylist.Revoke(ctx, principal.TokenID, principal.ExpiresAt)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Failed to logout")
+	}
+
+	if request.RefreshToken == "" {
+		return nil
+	}
+
+	token, err := u.repo.GetRefreshTokenByHash(ctx, jwtAuth.HashToken(request.RefreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Failed to logout")
+	}
+
+	// a user may only end their own sessions
+	if token.UserID != principal.UserID {
+		return nil
+	}
+
+	err = u.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Failed to logout")
+	}
+
+	return nil
+}
+
+func (u *UserUsecase) generateLoginResponse(ctx context.Context, user *userDomainEntity.User, familyID string) (*userDomainEntity.LoginResponse, error) {
+	roles, err := u.repo.GetRoles(ctx, int64(user.ID))
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to login")
+	}
+
+	permissions, err := u.repo.GetPermissions(ctx, int64(user.ID))
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to login")
 	}
 
 	dataJwt := &jwtAuth.JwtData{
-		UserID:    int64(user.ID),
-		Reference: user.Username,
+		UserID:      int64(user.ID),
+		Reference:   user.Username,
+		Roles:       roles,
+		Permissions: permissions,
 	}
 
 	// generate token
 	accessToken, expiredTime, err := u.jwt.GenerateToken(dataJwt)
 	if err != nil {
 		u.log.ErrorLog(ctx, err)
-		errMsg := errors.New("Failed to login")
-		return nil, errMsg
+		return nil, errorHelper.Wrap(err, "Failed to login")
+	}
+
+	refreshToken, refreshExpiredTime, err := u.jwt.GenerateRefreshToken()
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to login")
+	}
+
+	err = u.repo.CreateRefreshToken(ctx, &userDomainEntity.RefreshToken{
+		UserID:    int64(user.ID),
+		FamilyID:  familyID,
+		TokenHash: jwtAuth.HashToken(refreshToken),
+		ExpiresAt: *refreshExpiredTime,
+	})
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Failed to login")
 	}
 
 	result := &userDomainEntity.LoginResponse{
-		Token:      accessToken,
-		ExpiryTime: expiredTime,
+		Token:                  accessToken,
+		RefreshToken:           refreshToken,
+		ExpiryTime:             expiredTime,
+		RefreshTokenExpiryTime: refreshExpiredTime,
 	}
 
 	return result, nil
 }
 
-func (u *UserUsecase) Logout(ctx context.Context) error {
+func (u *UserUsecase) revokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	err := u.repo.RevokeRefreshTokenFamily(ctx, familyID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+	}
 
-	return nil
+	return errorHelper.ErrorRefreshTokenReused
 }
@@ -0,0 +1,218 @@
+package webhookHandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	webhookDomainInterface "github.com/ahsansandiah/dpo-test/api/webhook/domain"
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	webhookUsecase "github.com/ahsansandiah/dpo-test/api/webhook/usecase"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	res "github.com/ahsansandiah/dpo-test/packages/json"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+type Webhook struct {
+	log     log.Log
+	Json    res.Json
+	Usecase webhookDomainInterface.WebhookUsecase
+}
+
+func NewWebhookHandler(mgr manager.Manager) webhookDomainInterface.WebhookHandler {
+	handler := new(Webhook)
+	handler.Usecase = webhookUsecase.NewWebhookUsecase(mgr)
+	handler.Json = mgr.GetJson()
+
+	return handler
+}
+
+func (h *Webhook) GetAll() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		subscriptions, err := h.Usecase.GetAll(ctx)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success get data", subscriptions)
+	})
+}
+
+func (h *Webhook) GetByID() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		webhookIDStr := mux.Vars(r)["id"]
+		webhookID, err := strconv.ParseInt(webhookIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		subscription, err := h.Usecase.GetByID(ctx, webhookID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success get data", subscription)
+	})
+}
+
+func (h *Webhook) Create() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		var req *webhookDomainEntity.SubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		subscription, err := h.Usecase.Create(ctx, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.CreatedResponse(w, r, "Success created", subscription)
+	})
+}
+
+func (h *Webhook) Update() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		webhookIDStr := mux.Vars(r)["id"]
+		webhookID, err := strconv.ParseInt(webhookIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		var req *webhookDomainEntity.SubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+
+		subscription, err := h.Usecase.Update(ctx, webhookID, req)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, "Success updated", subscription)
+	})
+}
+
+func (h *Webhook) Delete() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		webhookIDStr := mux.Vars(r)["id"]
+		webhookID, err := strconv.ParseInt(webhookIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		if err := h.Usecase.Delete(ctx, webhookID); err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.SuccessResponse(w, r, fmt.Sprintf("Webhook with ID %d deleted successfully", webhookID), nil)
+	})
+}
+
+// GetDeliveries lists the delivery log, ?status=failed lists the deliveries
+// to redeliver.
+func (h *Webhook) GetDeliveries() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		queryParams := r.URL.Query()
+		limitStr := queryParams.Get("limit")
+		limit := paginateHelper.DefaultLimit
+		if limitStr != "" {
+			l, err := strconv.Atoi(limitStr)
+			if err == nil {
+				limit = l
+			}
+		}
+
+		var subscriptionID int64
+		if subscriptionIDStr := queryParams.Get("subscription_id"); subscriptionIDStr != "" {
+			ID, err := strconv.ParseInt(subscriptionIDStr, 10, 64)
+			if err != nil {
+				h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+				return
+			}
+			subscriptionID = ID
+		}
+
+		filter := &webhookDomainEntity.DeliveryFilter{
+			Status:         queryParams.Get("status"),
+			SubscriptionID: subscriptionID,
+			LIMIT:          limit,
+			Cursor:         queryParams.Get("cursor"),
+		}
+
+		result, err := h.Usecase.GetDeliveries(ctx, filter)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.PaginateResponse(w, r, "Success get data", result.Delivery, result.Paginate)
+	})
+}
+
+func (h *Webhook) Redeliver() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := traceHelper.Start(r.Context())
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		deliveryIDStr := mux.Vars(r)["id"]
+		deliveryID, err := strconv.ParseInt(deliveryIDStr, 10, 64)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, errorHelper.ErrorInvalidId)
+			return
+		}
+
+		delivery, err := h.Usecase.Redeliver(ctx, deliveryID)
+		if err != nil {
+			h.Json.ErrorResponse(w, r, err)
+			return
+		}
+
+		h.Json.AcceptedResponse(w, r, "Redelivery queued", delivery)
+	})
+}
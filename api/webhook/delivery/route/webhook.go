@@ -0,0 +1,23 @@
+package webhookRoute
+
+import (
+	webhookHandler "github.com/ahsansandiah/dpo-test/api/webhook/delivery/handler"
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewWebhookRoute(mgr manager.Manager, route *mux.Router) {
+	webhookHandler := webhookHandler.NewWebhookHandler(mgr)
+	can := mgr.GetMiddleware().RequirePermission
+
+	// the delivery routes go first, /webhooks/{id} would match them as well
+	route.Handle("/webhooks/deliveries", can(webhookDomainEntity.PermissionWebhookRead)(webhookHandler.GetDeliveries())).Methods("GET")
+	route.Handle("/webhooks/deliveries/{id}/redeliver", can(webhookDomainEntity.PermissionWebhookWrite)(webhookHandler.Redeliver())).Methods("POST")
+
+	route.Handle("/webhooks", can(webhookDomainEntity.PermissionWebhookRead)(webhookHandler.GetAll())).Methods("GET")
+	route.Handle("/webhooks/{id}", can(webhookDomainEntity.PermissionWebhookRead)(webhookHandler.GetByID())).Methods("GET")
+	route.Handle("/webhooks", can(webhookDomainEntity.PermissionWebhookWrite)(webhookHandler.Create())).Methods("POST")
+	route.Handle("/webhooks/{id}", can(webhookDomainEntity.PermissionWebhookWrite)(webhookHandler.Update())).Methods("PUT")
+	route.Handle("/webhooks/{id}", can(webhookDomainEntity.PermissionWebhookWrite)(webhookHandler.Delete())).Methods("DELETE")
+}
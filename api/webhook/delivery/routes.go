@@ -0,0 +1,14 @@
+package webhookRoutes
+
+import (
+	webhookRoute "github.com/ahsansandiah/dpo-test/api/webhook/delivery/route"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/gorilla/mux"
+)
+
+func NewRoutes(r *mux.Router, mgr manager.Manager) {
+	apiAuth := r.PathPrefix("").Subrouter()
+	apiAuth.Use(mgr.GetMiddleware().CheckToken, mgr.GetMiddleware().RateLimit("api"), mgr.GetMiddleware().Idempotency)
+
+	webhookRoute.NewWebhookRoute(mgr, apiAuth)
+}
@@ -0,0 +1,190 @@
+package webhookDomainEntity
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	customerDomainEntity "github.com/ahsansandiah/dpo-test/api/customer/domain/entity"
+	orderDomainEntity "github.com/ahsansandiah/dpo-test/api/order/domain/entity"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	validationHelper "github.com/ahsansandiah/dpo-test/helpers/validation"
+)
+
+const (
+	PermissionWebhookRead  = "webhooks:read"
+	PermissionWebhookWrite = "webhooks:write"
+)
+
+// Headers sent with every delivery. The signature is the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret,
+// prefixed with "sha256=".
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+)
+
+// EventTypeAll subscribes to every event type.
+const EventTypeAll = "*"
+
+// EventTypes are the domain events a subscription can receive.
+var EventTypes = []string{
+	customerDomainEntity.EventCustomerCreated,
+	customerDomainEntity.EventCustomerUpdated,
+	customerDomainEntity.EventCustomerDeleted,
+	customerDomainEntity.EventCustomerRestored,
+	orderDomainEntity.EventOrderCreated,
+	orderDomainEntity.EventOrderUpdated,
+	orderDomainEntity.EventOrderStatusChanged,
+	orderDomainEntity.EventOrderDeleted,
+	orderDomainEntity.EventOrderRestored,
+}
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// RulePublicURL is reported for subscription URLs reaching into our network.
+const RulePublicURL = "public_url"
+
+// SecretMinLength is the shortest secret a subscription accepts, without a
+// secret one is generated.
+const SecretMinLength = 16
+
+// Subscription is a partner endpoint receiving events. The secret is only
+// returned when the subscription is created.
+type Subscription struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedBy  *int64    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription receives the event type.
+func (s *Subscription) Subscribes(eventType string) bool {
+	for _, subscribed := range s.EventTypes {
+		if subscribed == EventTypeAll || subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type SubscriptionRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+// Delivery is the log of sending one event to one subscription.
+type Delivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseCode   *int            `json:"response_code"`
+	ResponseBody   string          `json:"response_body"`
+	LastError      string          `json:"last_error"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+type DeliveryListResponse struct {
+	Delivery []Delivery               `json:"delivery"`
+	Paginate *paginateHelper.Paginate `json:"paginate"`
+}
+
+type DeliveryFilter struct {
+	Status         string                 `json:"status"`
+	SubscriptionID int64                  `json:"subscription_id"`
+	LIMIT          int                    `json:"limit"`
+	Cursor         string                 `json:"cursor"`
+	Keyset         *paginateHelper.Cursor `json:"-"`
+}
+
+func (r *SubscriptionRequest) Validate() error {
+	v := validationHelper.New()
+
+	if v.Required("url", r.URL) && v.URL("url", r.URL) {
+		v.Check(PublicURL(r.URL), "url", RulePublicURL, "url must not point to a loopback, private or link-local address")
+	}
+
+	v.Length("secret", r.Secret, SecretMinLength, 255)
+
+	if v.Check(len(r.EventTypes) > 0, "event_types", validationHelper.RuleRequired, "event_types is required") {
+		for i, eventType := range r.EventTypes {
+			field := fmt.Sprintf("event_types[%d]", i)
+			v.Check(eventType == EventTypeAll || oneOf(eventType, EventTypes), field, validationHelper.RuleOneOf, fmt.Sprintf("%s must be %s or one of %s", field, EventTypeAll, strings.Join(EventTypes, ", ")))
+		}
+	}
+
+	return v.Err()
+}
+
+func (r *DeliveryFilter) Validate() error {
+	v := validationHelper.New()
+
+	statuses := []string{DeliveryStatusPending, DeliveryStatusSucceeded, DeliveryStatusFailed}
+	v.Check(r.Status == "" || oneOf(r.Status, statuses), "status", validationHelper.RuleOneOf, fmt.Sprintf("status must be one of %s", strings.Join(statuses, ", ")))
+
+	return v.Err()
+}
+
+func oneOf(value string, values []string) bool {
+	for _, known := range values {
+		if value == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PublicURL reports whether the host of rawURL may be public. Names are
+// resolved when the delivery is sent, only localhost and addresses are
+// checked here.
+func PublicURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return PublicIP(ip)
+	}
+
+	return true
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, not covered by
+// net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP reports whether deliveries may be sent to ip.
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
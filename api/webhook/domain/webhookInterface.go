@@ -0,0 +1,49 @@
+package webhookDomainInterface
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+)
+
+type WebhookHandler interface {
+	GetAll() http.Handler
+	GetByID() http.Handler
+	Create() http.Handler
+	Update() http.Handler
+	Delete() http.Handler
+	GetDeliveries() http.Handler
+	Redeliver() http.Handler
+}
+
+type WebhookUsecase interface {
+	GetAll(ctx context.Context) ([]webhookDomainEntity.Subscription, error)
+	GetByID(ctx context.Context, ID int64) (*webhookDomainEntity.Subscription, error)
+	Create(ctx context.Context, request *webhookDomainEntity.SubscriptionRequest) (*webhookDomainEntity.Subscription, error)
+	Update(ctx context.Context, ID int64, request *webhookDomainEntity.SubscriptionRequest) (*webhookDomainEntity.Subscription, error)
+	Delete(ctx context.Context, ID int64) error
+	GetDeliveries(ctx context.Context, filter *webhookDomainEntity.DeliveryFilter) (*webhookDomainEntity.DeliveryListResponse, error)
+	// Redeliver queues a failed delivery to be sent again.
+	Redeliver(ctx context.Context, ID int64) (*webhookDomainEntity.Delivery, error)
+	// Publish logs a pending delivery of the event for every subscription
+	// receiving it, the dispatcher sends them.
+	Publish(ctx context.Context, event outbox.Event) error
+}
+
+type WebhookRepository interface {
+	GetAll(ctx context.Context, activeOnly bool) ([]webhookDomainEntity.Subscription, error)
+	GetById(ctx context.Context, ID int64) (*webhookDomainEntity.Subscription, error)
+	Create(ctx context.Context, subscription *webhookDomainEntity.Subscription) (int64, error)
+	Update(ctx context.Context, subscription *webhookDomainEntity.Subscription) error
+	Delete(ctx context.Context, ID int64) error
+	GetDeliveries(ctx context.Context, filter *webhookDomainEntity.DeliveryFilter) ([]webhookDomainEntity.Delivery, error)
+	GetDeliveryById(ctx context.Context, ID int64) (*webhookDomainEntity.Delivery, error)
+	CreateDelivery(ctx context.Context, delivery *webhookDomainEntity.Delivery) (bool, error)
+	UpdateDelivery(ctx context.Context, delivery *webhookDomainEntity.Delivery) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhookDomainEntity.Delivery, error)
+	RequeueDelivery(ctx context.Context, ID int64) error
+	RetryDelivery(ctx context.Context, ID int64) (bool, error)
+}
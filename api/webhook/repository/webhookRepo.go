@@ -0,0 +1,387 @@
+package webhookRepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	webhookDomainInterface "github.com/ahsansandiah/dpo-test/api/webhook/domain"
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+)
+
+type Webhook struct {
+	DB  transactionDatabase.Transaction
+	log log.Log
+	cfg *config.Config
+}
+
+func NewWebhookRepository(mgr manager.Manager) webhookDomainInterface.WebhookRepository {
+	repo := new(Webhook)
+	repo.DB = mgr.GetTransaction()
+	repo.log = mgr.GetLog()
+	repo.cfg = mgr.GetConfig()
+
+	return repo
+}
+
+const subscriptionColumns = "id, url, secret, event_types, is_active, created_by, created_at, updated_at"
+
+func scanSubscription(scan func(dest ...interface{}) error) (*webhookDomainEntity.Subscription, error) {
+	var subscription webhookDomainEntity.Subscription
+	var eventTypes []byte
+	if err := scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &subscription.IsActive, &subscription.CreatedBy, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventTypes, &subscription.EventTypes); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *Webhook) GetAll(ctx context.Context, activeOnly bool) ([]webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions"
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []webhookDomainEntity.Subscription{}
+	for rows.Next() {
+		subscription, err := scanSubscription(rows.Scan)
+		if err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Webhook) GetById(ctx context.Context, ID int64) (*webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions WHERE id = ?"
+	subscription, err := scanSubscription(r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorWebhookNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *Webhook) Create(ctx context.Context, subscription *webhookDomainEntity.Subscription) (int64, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	query := "INSERT INTO webhook_subscriptions (url, secret, event_types, is_active, created_by) VALUES (?, ?, ?, ?, ?)"
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, subscription.URL, subscription.Secret, string(eventTypes), subscription.IsActive, subscription.CreatedBy)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	ID, err := result.LastInsertId()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return 0, err
+	}
+
+	return ID, nil
+}
+
+func (r *Webhook) Update(ctx context.Context, subscription *webhookDomainEntity.Subscription) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	eventTypes, err := json.Marshal(subscription.EventTypes)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	query := "UPDATE webhook_subscriptions SET url = ?, secret = ?, event_types = ?, is_active = ? WHERE id = ?"
+	_, err = r.DB.Executor(ctx).ExecContext(ctx, query, subscription.URL, subscription.Secret, string(eventTypes), subscription.IsActive, subscription.ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a subscription together with its delivery log.
+func (r *Webhook) Delete(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = ?", ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+const deliveryColumns = "d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.response_code, COALESCE(d.response_body, ''), COALESCE(d.last_error, ''), d.last_attempt_at, d.created_at, d.updated_at"
+
+func scanDelivery(scan func(dest ...interface{}) error) (*webhookDomainEntity.Delivery, error) {
+	var delivery webhookDomainEntity.Delivery
+	var payload []byte
+	if err := scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status, &delivery.Attempts, &delivery.ResponseCode, &delivery.ResponseBody, &delivery.LastError, &delivery.LastAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+
+	return &delivery, nil
+}
+
+func (r *Webhook) GetDeliveries(ctx context.Context, filter *webhookDomainEntity.DeliveryFilter) ([]webhookDomainEntity.Delivery, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries d WHERE TRUE"
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		query += " AND d.status = ?"
+		args = append(args, filter.Status)
+	}
+
+	if filter.SubscriptionID != 0 {
+		query += " AND d.subscription_id = ?"
+		args = append(args, filter.SubscriptionID)
+	}
+
+	backward := false
+	if filter.Keyset != nil {
+		condition, keysetArgs := filter.Keyset.Condition("d")
+		query += condition
+		args = append(args, keysetArgs...)
+		backward = filter.Keyset.Backward
+	}
+
+	query += paginateHelper.OrderBy("d", paginateHelper.SortByID, backward)
+	query += " LIMIT ?"
+	args = append(args, filter.LIMIT)
+
+	rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhookDomainEntity.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows.Scan)
+		if err != nil {
+			r.log.ErrorLog(ctx, err)
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err = rows.Err(); err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *Webhook) GetDeliveryById(ctx context.Context, ID int64) (*webhookDomainEntity.Delivery, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries d WHERE d.id = ?"
+	delivery, err := scanDelivery(r.DB.Executor(ctx).QueryRowContext(ctx, query, ID).Scan)
+	if err == sql.ErrNoRows {
+		return nil, errorHelper.ErrorWebhookDeliveryNotFound
+	}
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// CreateDelivery logs a delivery and sets its id. An event replayed by the
+// outbox already has its delivery to the subscription, which is left as it
+// is and false is returned.
+func (r *Webhook) CreateDelivery(ctx context.Context, delivery *webhookDomainEntity.Delivery) (bool, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	// a duplicate changes nothing and affects no rows
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status) VALUES (?, ?, ?, ?, ?)
+              ON DUPLICATE KEY UPDATE id = id`
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, delivery.SubscriptionID, delivery.EventID, delivery.EventType, string(delivery.Payload), delivery.Status)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	delivery.ID, err = result.LastInsertId()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Webhook) UpdateDelivery(ctx context.Context, delivery *webhookDomainEntity.Delivery) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := `UPDATE webhook_deliveries
+              SET status = ?, attempts = ?, response_code = ?, response_body = ?, last_error = ?, last_attempt_at = ?
+              WHERE id = ?`
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.ResponseBody, delivery.LastError, delivery.LastAttemptAt, delivery.ID)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries due for sending and
+// hides them from other dispatchers for the lease, a delivery is claimed
+// again when the dispatcher sending it dies.
+func (r *Webhook) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhookDomainEntity.Delivery, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	deliveries := []webhookDomainEntity.Delivery{}
+	err := r.DB.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		query := "SELECT " + deliveryColumns + ` FROM webhook_deliveries d
+                  WHERE d.status = ? AND d.next_attempt_at <= ?
+                  ORDER BY d.id
+                  LIMIT ?
+                  FOR UPDATE SKIP LOCKED`
+		rows, err := r.DB.Executor(ctx).QueryContext(ctx, query, webhookDomainEntity.DeliveryStatusPending, now, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			delivery, err := scanDelivery(rows.Scan)
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, *delivery)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		args := make([]interface{}, 0, len(deliveries)+1)
+		args = append(args, now.Add(lease))
+		for _, delivery := range deliveries {
+			args = append(args, delivery.ID)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(deliveries)), ", ")
+		_, err = r.DB.Executor(ctx).ExecContext(ctx, "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id IN ("+placeholders+")", args...)
+		return err
+	})
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RequeueDelivery makes a pending delivery due now, it puts back a delivery
+// a dispatcher claimed but could not finish.
+func (r *Webhook) RequeueDelivery(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ? AND status = ?"
+	_, err := r.DB.Executor(ctx).ExecContext(ctx, query, time.Now(), ID, webhookDomainEntity.DeliveryStatusPending)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// RetryDelivery makes a failed delivery pending and due again. It reports
+// false when the delivery is not failed, a pending one may be in the hands
+// of a dispatcher.
+func (r *Webhook) RetryDelivery(ctx context.Context, ID int64) (bool, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	query := "UPDATE webhook_deliveries SET status = ?, next_attempt_at = ? WHERE id = ? AND status = ?"
+	result, err := r.DB.Executor(ctx).ExecContext(ctx, query, webhookDomainEntity.DeliveryStatusPending, time.Now(), ID, webhookDomainEntity.DeliveryStatusFailed)
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		r.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,102 @@
+package webhookUsecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+)
+
+const (
+	dispatchPollInterval = time.Second
+
+	// maxConcurrentDeliveries bounds the deliveries a dispatcher sends at once.
+	maxConcurrentDeliveries = 10
+)
+
+// Dispatcher sends the pending deliveries: those logged by Publish, queued
+// by Redeliver, cut short by a shutdown or left claimed by an instance that
+// died once their lease ran out.
+type Dispatcher struct {
+	usecase      *WebhookUsecase
+	pollInterval time.Duration
+	// lease hides a claimed delivery from other dispatchers, it must outlast
+	// the retry window.
+	lease time.Duration
+}
+
+func NewDispatcher(mgr manager.Manager) *Dispatcher {
+	usecase := NewWebhookUsecase(mgr).(*WebhookUsecase)
+
+	return &Dispatcher{
+		usecase:      usecase,
+		pollInterval: dispatchPollInterval,
+		lease:        usecase.retryWindow + 5*time.Minute,
+	}
+}
+
+// Run sends deliveries until ctx is cancelled, then waits for the sends in
+// flight to put back what they could not finish.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, maxConcurrentDeliveries)
+	for {
+		d.dispatch(ctx, &wg, slots)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// dispatch claims as many due deliveries as there are free slots and sends
+// each in the background.
+func (d *Dispatcher) dispatch(ctx context.Context, wg *sync.WaitGroup, slots chan struct{}) {
+	free := cap(slots) - len(slots)
+	if free == 0 {
+		return
+	}
+
+	deliveries, err := d.usecase.repo.ClaimDeliveries(ctx, free, d.lease)
+	if err != nil {
+		return
+	}
+
+	for i := range deliveries {
+		delivery := deliveries[i]
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := d.send(ctx, &delivery); err != nil {
+				d.usecase.log.ErrorLog(ctx, err)
+			}
+		}()
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *webhookDomainEntity.Delivery) error {
+	subscription, err := d.usecase.repo.GetById(ctx, delivery.SubscriptionID)
+	// deleting the subscription deletes its deliveries too
+	if err == errorHelper.ErrorWebhookNotFound {
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		return d.usecase.requeue(ctx, delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.usecase.send(ctx, subscription, delivery)
+}
@@ -0,0 +1,38 @@
+package webhookUsecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1,"type":"OrderCreated"}`)
+
+	signature := Sign("whsec_0123456789abcdef", 1760745600, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.Equal(t, signature, Sign("whsec_0123456789abcdef", 1760745600, body))
+	assert.NotEqual(t, signature, Sign("whsec_0123456789abcdef", 1760745601, body))
+	assert.NotEqual(t, signature, Sign("another_secret_0123", 1760745600, body))
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", 1760745600, body)
+
+	assert.Equal(t, "sha256=fef7f28bdc1dc442e05c4d3519c6aeddefd5fbca10c7aff41dc6edae0eeaa883", signature)
+	assert.True(t, Verify("secret", 1760745600, body, signature))
+	assert.False(t, Verify("secret", 1760745600, []byte(`{"id":2}`), signature))
+	assert.False(t, Verify("secret", 1760745600, body, "sha256=00"))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := generateSecret()
+	assert.NoError(t, err)
+	second, _ := generateSecret()
+
+	assert.Len(t, first, secretLength*2)
+	assert.NotEqual(t, first, second)
+}
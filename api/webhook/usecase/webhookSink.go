@@ -0,0 +1,28 @@
+package webhookUsecase
+
+import (
+	"context"
+
+	webhookDomainInterface "github.com/ahsansandiah/dpo-test/api/webhook/domain"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+)
+
+// Sink hands the events of the outbox to the webhook subscriptions. An event
+// counts as published once its deliveries are logged, the Dispatcher sends
+// and retries them, not the outbox.
+type Sink struct {
+	usecase webhookDomainInterface.WebhookUsecase
+}
+
+func NewSink(mgr manager.Manager) outbox.Sink {
+	return &Sink{usecase: NewWebhookUsecase(mgr)}
+}
+
+func (s *Sink) Name() string {
+	return "webhook_subscriptions"
+}
+
+func (s *Sink) Publish(ctx context.Context, event outbox.Event) error {
+	return s.usecase.Publish(ctx, event)
+}
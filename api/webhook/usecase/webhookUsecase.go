@@ -0,0 +1,446 @@
+package webhookUsecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	webhookDomainInterface "github.com/ahsansandiah/dpo-test/api/webhook/domain"
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	webhookRepository "github.com/ahsansandiah/dpo-test/api/webhook/repository"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
+	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	httpClient "github.com/ahsansandiah/dpo-test/packages/client"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	"github.com/cenkalti/backoff"
+)
+
+const (
+	defaultRetryMaxElapsed = time.Minute
+
+	// maxResponseBodyLength is how much of a response the delivery log keeps.
+	maxResponseBodyLength = 1000
+
+	// settleTimeout bounds storing the outcome of a send once a shutdown
+	// cancelled its context.
+	settleTimeout = 5 * time.Second
+)
+
+type WebhookUsecase struct {
+	log         log.Log
+	cfg         *config.Config
+	repo        webhookDomainInterface.WebhookRepository
+	http        httpClient.Http
+	retryWindow time.Duration
+}
+
+func NewWebhookUsecase(mgr manager.Manager) webhookDomainInterface.WebhookUsecase {
+	usecase := new(WebhookUsecase)
+	usecase.log = mgr.GetLog()
+	usecase.cfg = mgr.GetConfig()
+	usecase.repo = webhookRepository.NewWebhookRepository(mgr)
+	usecase.http = newDeliveryClient(usecase.cfg, usecase.log, mgr.GetMetrics(), webhookDomainEntity.PublicIP)
+	usecase.retryWindow = time.Duration(usecase.cfg.WebhookRetryMaxElapsed) * time.Second
+	if usecase.retryWindow <= 0 {
+		usecase.retryWindow = defaultRetryMaxElapsed
+	}
+
+	return usecase
+}
+
+// newDeliveryClient calls subscriptions only on the addresses allowed
+// accepts, checked on every connection, and does not follow redirects, a
+// subscription could otherwise have the server call internal hosts.
+func newDeliveryClient(cfg *config.Config, lg log.Log, mt metrics.Metrics, allowed func(ip net.IP) bool) httpClient.Http {
+	client := httpClient.NewHttp(cfg, lg, mt, httpClient.WithAllowedIP(allowed), httpClient.WithoutRedirects())
+	client.Connect()
+
+	return client
+}
+
+func (u *WebhookUsecase) GetAll(ctx context.Context) ([]webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	subscriptions, err := u.repo.GetAll(ctx, false)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook subscriptions")
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (u *WebhookUsecase) GetByID(ctx context.Context, ID int64) (*webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	subscription, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook subscription")
+	}
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+// Create stores a subscription and returns it with its secret, which is
+// generated when the request has none. The secret is not returned again.
+func (u *WebhookUsecase) Create(ctx context.Context, request *webhookDomainEntity.SubscriptionRequest) (*webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	subscription := &webhookDomainEntity.Subscription{
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+		IsActive:   request.IsActive == nil || *request.IsActive,
+	}
+
+	if principal, ok := principalAuth.FromContext(ctx); ok {
+		subscription.CreatedBy = &principal.UserID
+	}
+
+	if subscription.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			u.log.ErrorLog(ctx, err)
+			return nil, errorHelper.Wrap(err, "Error inserting webhook subscription")
+		}
+		subscription.Secret = secret
+	}
+
+	ID, err := u.repo.Create(ctx, subscription)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error inserting webhook subscription")
+	}
+
+	result, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook subscription")
+	}
+
+	return result, nil
+}
+
+// Update changes the fields sent in the request, sending a secret rotates it.
+func (u *WebhookUsecase) Update(ctx context.Context, ID int64, request *webhookDomainEntity.SubscriptionRequest) (*webhookDomainEntity.Subscription, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	subscription, err := u.repo.GetById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook subscription")
+	}
+
+	if request.URL != "" {
+		subscription.URL = request.URL
+	}
+
+	if request.Secret != "" {
+		subscription.Secret = request.Secret
+	}
+
+	if request.EventTypes != nil {
+		subscription.EventTypes = request.EventTypes
+	}
+
+	if request.IsActive != nil {
+		subscription.IsActive = *request.IsActive
+	}
+
+	updated := &webhookDomainEntity.SubscriptionRequest{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventTypes: subscription.EventTypes,
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Update(ctx, subscription); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error updating webhook subscription")
+	}
+
+	return u.GetByID(ctx, ID)
+}
+
+func (u *WebhookUsecase) Delete(ctx context.Context, ID int64) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if _, err := u.repo.GetById(ctx, ID); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Error fetching webhook subscription")
+	}
+
+	if err := u.repo.Delete(ctx, ID); err != nil {
+		u.log.ErrorLog(ctx, err)
+		return errorHelper.Wrap(err, "Error deleting webhook subscription")
+	}
+
+	return nil
+}
+
+func (u *WebhookUsecase) GetDeliveries(ctx context.Context, filter *webhookDomainEntity.DeliveryFilter) (*webhookDomainEntity.DeliveryListResponse, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.LIMIT = paginateHelper.ValidateLimit(filter.LIMIT)
+
+	if filter.Cursor != "" {
+		cursor, err := paginateHelper.ParseCursor(filter.Cursor, u.cfg.PaginateCursorSecret)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != paginateHelper.SortByID {
+			return nil, errorHelper.ErrorInvalidCursor
+		}
+		filter.Keyset = cursor
+	}
+
+	// fetch one extra delivery to know whether another page exists
+	limit := filter.LIMIT
+	filter.LIMIT = limit + 1
+	deliveries, err := u.repo.GetDeliveries(ctx, filter)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook deliveries")
+	}
+	filter.LIMIT = limit
+
+	hasMore := len(deliveries) > limit
+	if hasMore {
+		deliveries = deliveries[:limit]
+	}
+
+	if filter.Keyset != nil && filter.Keyset.Backward {
+		for i, j := 0, len(deliveries)-1; i < j; i, j = i+1, j-1 {
+			deliveries[i], deliveries[j] = deliveries[j], deliveries[i]
+		}
+	}
+
+	var first, last *paginateHelper.Cursor
+	if len(deliveries) > 0 {
+		firstCursor := paginateHelper.NewCursor(paginateHelper.SortByID, deliveries[0].CreatedAt, deliveries[0].ID)
+		lastCursor := paginateHelper.NewCursor(paginateHelper.SortByID, deliveries[len(deliveries)-1].CreatedAt, deliveries[len(deliveries)-1].ID)
+		first, last = &firstCursor, &lastCursor
+	}
+
+	paginate, err := paginateHelper.NewPaginate(u.cfg.PaginateCursorSecret, filter.Keyset, hasMore, first, last)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	result := &webhookDomainEntity.DeliveryListResponse{
+		Delivery: deliveries,
+		Paginate: paginate,
+	}
+
+	return result, nil
+}
+
+// Redeliver queues a failed delivery to be sent again by the dispatcher.
+func (u *WebhookUsecase) Redeliver(ctx context.Context, ID int64) (*webhookDomainEntity.Delivery, error) {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	delivery, err := u.repo.GetDeliveryById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook delivery")
+	}
+
+	if delivery.Status != webhookDomainEntity.DeliveryStatusFailed {
+		return nil, errorHelper.ErrorWebhookNotFailed
+	}
+
+	// the status is checked again by the update, a concurrent redelivery
+	// may have queued it already
+	retried, err := u.repo.RetryDelivery(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error redelivering webhook")
+	}
+	if !retried {
+		return nil, errorHelper.ErrorWebhookNotFailed
+	}
+
+	delivery, err = u.repo.GetDeliveryById(ctx, ID)
+	if err != nil {
+		u.log.ErrorLog(ctx, err)
+		return nil, errorHelper.Wrap(err, "Error fetching webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+func (u *WebhookUsecase) Publish(ctx context.Context, event outbox.Event) error {
+	ctx, span := traceHelper.Start(ctx)
+	defer span.End()
+
+	subscriptions, err := u.repo.GetAll(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for i := range subscriptions {
+		subscription := subscriptions[i]
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+
+		delivery := &webhookDomainEntity.Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         webhookDomainEntity.DeliveryStatusPending,
+		}
+
+		// a replayed event was handed to the subscription already
+		if _, err := u.repo.CreateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send posts a delivery to its subscription, retrying with an exponential
+// backoff until it succeeds, the endpoint rejects it or the retry window
+// closes. Every attempt is written to the delivery log. A send cut short by
+// ctx leaves the delivery pending for the next dispatcher.
+func (u *WebhookUsecase) send(ctx context.Context, subscription *webhookDomainEntity.Subscription, delivery *webhookDomainEntity.Delivery) error {
+	retry := backoff.NewExponentialBackOff()
+	retry.MaxElapsedTime = u.retryWindow
+
+	err := backoff.Retry(func() error {
+		err := u.attempt(ctx, subscription, delivery)
+		if err != nil && !isRetryable(err) {
+			return backoff.Permanent(err)
+		}
+
+		return err
+	}, backoff.WithContext(retry, ctx))
+
+	if err != nil && ctx.Err() != nil {
+		return u.requeue(ctx, delivery)
+	}
+
+	delivery.Status = webhookDomainEntity.DeliveryStatusSucceeded
+	if err != nil {
+		delivery.Status = webhookDomainEntity.DeliveryStatusFailed
+	}
+
+	// a delivery that went through just before a shutdown is still recorded
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+
+	return u.repo.UpdateDelivery(ctx, delivery)
+}
+
+// requeue puts back a delivery cut short by the cancellation of ctx.
+func (u *WebhookUsecase) requeue(ctx context.Context, delivery *webhookDomainEntity.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+
+	return u.repo.RequeueDelivery(ctx, delivery.ID)
+}
+
+// attempt posts a delivery once and logs the response.
+func (u *WebhookUsecase) attempt(ctx context.Context, subscription *webhookDomainEntity.Subscription, delivery *webhookDomainEntity.Delivery) error {
+	now := time.Now()
+	header := map[string]string{
+		"Content-Type":                      "application/json",
+		webhookDomainEntity.HeaderTimestamp: strconv.FormatInt(now.Unix(), 10),
+		webhookDomainEntity.HeaderSignature: Sign(subscription.Secret, now.Unix(), delivery.Payload),
+		webhookDomainEntity.HeaderDelivery:  strconv.FormatInt(delivery.ID, 10),
+		webhookDomainEntity.HeaderEvent:     delivery.EventType,
+	}
+
+	res, err := u.http.Do(ctx, httpClient.Request{
+		Method: http.MethodPost,
+		URL:    subscription.URL,
+		Header: header,
+		Body:   delivery.Payload,
+	})
+	// the receiver accepted the event, only its answer was too long to keep
+	if errors.Is(err, httpClient.ErrResponseTooLarge) && res.StatusCode < http.StatusMultipleChoices {
+		err = nil
+	}
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseBody = ""
+	delivery.ResponseCode = nil
+	if res != nil {
+		delivery.ResponseBody = truncate(string(res.Body), maxResponseBodyLength)
+		delivery.ResponseCode = &res.StatusCode
+	}
+	delivery.LastError = ""
+	if err != nil {
+		delivery.LastError = err.Error()
+	}
+
+	if err := u.repo.UpdateDelivery(ctx, delivery); err != nil {
+		u.log.ErrorLog(ctx, err)
+	}
+
+	return err
+}
+
+// isRetryable tells whether a failed delivery may succeed later: network
+// errors, timeouts, rate limits and server errors are retried, other client
+// errors and private addresses are not.
+func isRetryable(err error) bool {
+	if errors.Is(err, httpClient.ErrAddressNotAllowed) {
+		return false
+	}
+
+	var statusErr *httpClient.StatusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+
+	code := statusErr.StatusCode
+	return code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	// cutting may split a multi byte character
+	return strings.ToValidUTF8(value[:length], "")
+}
@@ -0,0 +1,215 @@
+package webhookUsecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	webhookDomainInterface "github.com/ahsansandiah/dpo-test/api/webhook/domain"
+	webhookDomainEntity "github.com/ahsansandiah/dpo-test/api/webhook/domain/entity"
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	httpClient "github.com/ahsansandiah/dpo-test/packages/client"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(errors.New("connection refused")))
+	assert.True(t, isRetryable(&httpClient.StatusError{StatusCode: 503}))
+	assert.True(t, isRetryable(&httpClient.StatusError{StatusCode: 429}))
+	assert.True(t, isRetryable(fmt.Errorf("calling: %w", &httpClient.StatusError{StatusCode: 408})))
+	assert.False(t, isRetryable(&httpClient.StatusError{StatusCode: 404}))
+	assert.False(t, isRetryable(&httpClient.StatusError{StatusCode: 400}))
+}
+
+func TestTruncateKeepsValidUTF8(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 10))
+	assert.Equal(t, "ab", truncate("abé", 3))
+}
+
+var subscription = webhookDomainEntity.Subscription{ID: 1, URL: "https://203.0.113.10/hook", EventTypes: []string{"*"}, IsActive: true}
+
+func TestPublishLogsReplayedEventsOnce(t *testing.T) {
+	repo := webhookDomainInterface.NewMockWebhookRepository(gomock.NewController(t))
+	repo.EXPECT().GetAll(gomock.Any(), true).Return([]webhookDomainEntity.Subscription{subscription}, nil).Times(2)
+
+	var created []*webhookDomainEntity.Delivery
+	repo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, delivery *webhookDomainEntity.Delivery) (bool, error) {
+		created = append(created, delivery)
+		return len(created) == 1, nil
+	}).Times(2)
+
+	usecase := &WebhookUsecase{repo: repo}
+	event := outbox.Event{ID: 7, Type: "order.created"}
+
+	assert.NoError(t, usecase.Publish(context.Background(), event))
+	assert.NoError(t, usecase.Publish(context.Background(), event))
+
+	assert.Equal(t, int64(1), created[0].SubscriptionID)
+	assert.Equal(t, int64(7), created[0].EventID)
+	assert.Equal(t, webhookDomainEntity.DeliveryStatusPending, created[0].Status)
+}
+
+func TestDispatcherRequeuesSendsCutShortByShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	sending := make(chan struct{})
+	client := httpClient.NewMockHttp(ctrl)
+	client.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req httpClient.Request) (*httpClient.Response, error) {
+		close(sending)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	repo := webhookDomainInterface.NewMockWebhookRepository(ctrl)
+	gomock.InOrder(
+		repo.EXPECT().ClaimDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return([]webhookDomainEntity.Delivery{{ID: 3, SubscriptionID: 1, Status: webhookDomainEntity.DeliveryStatusPending}}, nil),
+		repo.EXPECT().ClaimDeliveries(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes(),
+	)
+	repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(&subscription, nil)
+	repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().RequeueDelivery(gomock.Any(), int64(3)).Return(nil)
+
+	dispatcher := &Dispatcher{
+		usecase:      &WebhookUsecase{repo: repo, http: client, log: logger.NewLog(&config.Config{LogLevel: "panic"}), retryWindow: time.Minute},
+		pollInterval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	<-sending
+	cancel()
+	<-done
+}
+
+func TestSubscriptionURLMustBePublic(t *testing.T) {
+	for _, url := range []string{"http://localhost/hook", "http://127.0.0.1/hook", "http://10.1.2.3/hook", "http://[::1]/hook", "http://169.254.169.254/latest", "http://100.64.0.1/hook"} {
+		assert.False(t, webhookDomainEntity.PublicURL(url), url)
+	}
+	assert.True(t, webhookDomainEntity.PublicURL("https://partner.example.com/hook"))
+	assert.True(t, webhookDomainEntity.PublicURL("https://203.0.113.10/hook"))
+}
+
+func newTestDeliveryClient(t *testing.T, allowed func(ip net.IP) bool) httpClient.Http {
+	cfg := &config.Config{LogLevel: "panic"}
+	mt, err := metrics.NewMetrics(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newDeliveryClient(cfg, logger.NewLog(cfg), mt, allowed)
+}
+
+func TestDeliveryRefusesPrivateAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a loopback receiver was called")
+	}))
+	defer receiver.Close()
+
+	repo := webhookDomainInterface.NewMockWebhookRepository(gomock.NewController(t))
+	repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil)
+
+	usecase := &WebhookUsecase{repo: repo, http: newTestDeliveryClient(t, webhookDomainEntity.PublicIP)}
+	err := usecase.attempt(context.Background(), &webhookDomainEntity.Subscription{URL: receiver.URL}, &webhookDomainEntity.Delivery{ID: 3})
+
+	assert.ErrorIs(t, err, httpClient.ErrAddressNotAllowed)
+	assert.False(t, isRetryable(err))
+}
+
+func TestDeliveryDoesNotFollowRedirects(t *testing.T) {
+	var internalCalls int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&internalCalls, 1)
+	}))
+	defer internal.Close()
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/latest/meta-data", http.StatusTemporaryRedirect)
+	}))
+	defer redirector.Close()
+
+	// the test servers listen on loopback, only the redirect is refused here
+	allowAll := func(net.IP) bool { return true }
+	repo := webhookDomainInterface.NewMockWebhookRepository(gomock.NewController(t))
+	repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).Return(nil)
+
+	usecase := &WebhookUsecase{repo: repo, http: newTestDeliveryClient(t, allowAll)}
+	err := usecase.attempt(context.Background(), &webhookDomainEntity.Subscription{URL: redirector.URL}, &webhookDomainEntity.Delivery{ID: 3})
+
+	var statusErr *httpClient.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusErr.StatusCode)
+	assert.False(t, isRetryable(err))
+	assert.Zero(t, atomic.LoadInt32(&internalCalls))
+}
+
+func TestRedeliverQueuesFailedDeliveriesOnly(t *testing.T) {
+	repo := webhookDomainInterface.NewMockWebhookRepository(gomock.NewController(t))
+	usecase := &WebhookUsecase{repo: repo, log: logger.NewLog(&config.Config{LogLevel: "panic"})}
+
+	repo.EXPECT().GetDeliveryById(gomock.Any(), int64(3)).Return(&webhookDomainEntity.Delivery{ID: 3, Status: webhookDomainEntity.DeliveryStatusPending}, nil)
+	_, err := usecase.Redeliver(context.Background(), 3)
+	assert.ErrorIs(t, err, errorHelper.ErrorWebhookNotFailed)
+
+	gomock.InOrder(
+		repo.EXPECT().GetDeliveryById(gomock.Any(), int64(3)).Return(&webhookDomainEntity.Delivery{ID: 3, Status: webhookDomainEntity.DeliveryStatusFailed}, nil),
+		repo.EXPECT().RetryDelivery(gomock.Any(), int64(3)).Return(true, nil),
+		repo.EXPECT().GetDeliveryById(gomock.Any(), int64(3)).Return(&webhookDomainEntity.Delivery{ID: 3, Status: webhookDomainEntity.DeliveryStatusPending}, nil),
+	)
+	delivery, err := usecase.Redeliver(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, webhookDomainEntity.DeliveryStatusPending, delivery.Status)
+}
+
+func TestRedeliverLosesToConcurrentRedelivery(t *testing.T) {
+	repo := webhookDomainInterface.NewMockWebhookRepository(gomock.NewController(t))
+	usecase := &WebhookUsecase{repo: repo, log: logger.NewLog(&config.Config{LogLevel: "panic"})}
+
+	repo.EXPECT().GetDeliveryById(gomock.Any(), int64(3)).Return(&webhookDomainEntity.Delivery{ID: 3, Status: webhookDomainEntity.DeliveryStatusFailed}, nil)
+	repo.EXPECT().RetryDelivery(gomock.Any(), int64(3)).Return(false, nil)
+
+	_, err := usecase.Redeliver(context.Background(), 3)
+	assert.ErrorIs(t, err, errorHelper.ErrorWebhookNotFailed)
+}
+
+func TestSendRecordsSuccessRightBeforeShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := httpClient.NewMockHttp(ctrl)
+	client.EXPECT().Do(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, httpClient.Request) (*httpClient.Response, error) {
+		cancel()
+		return &httpClient.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	// the attempt is logged with the cancelled context, the outcome without it
+	var status string
+	repo := webhookDomainInterface.NewMockWebhookRepository(ctrl)
+	repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, delivery *webhookDomainEntity.Delivery) error {
+		if ctx.Err() == nil {
+			status = delivery.Status
+		}
+		return ctx.Err()
+	}).Times(2)
+
+	usecase := &WebhookUsecase{repo: repo, http: client, log: logger.NewLog(&config.Config{LogLevel: "panic"}), retryWindow: time.Minute}
+	err := usecase.send(ctx, &subscription, &webhookDomainEntity.Delivery{ID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, webhookDomainEntity.DeliveryStatusSucceeded, status)
+}
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/ahsansandiah/dpo-test/packages/manager"
-	"github.com/ahsansandiah/dpo-test/packages/server"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
 
+	auditRoutes "github.com/ahsansandiah/dpo-test/api/audit/delivery"
 	customerRoutes "github.com/ahsansandiah/dpo-test/api/customer/delivery"
 	orderRoutes "github.com/ahsansandiah/dpo-test/api/order/delivery"
+	productRoutes "github.com/ahsansandiah/dpo-test/api/product/delivery"
 	userRoutes "github.com/ahsansandiah/dpo-test/api/user/delivery"
+	webhookRoutes "github.com/ahsansandiah/dpo-test/api/webhook/delivery"
+	webhookUsecase "github.com/ahsansandiah/dpo-test/api/webhook/usecase"
 )
 
+const defaultReadinessDelay = 5 * time.Second
+
 func run() error {
 	mgr, err := manager.NewInit()
 	if err != nil {
@@ -27,17 +34,82 @@ func run() error {
 	time.Local = tzLocation
 
 	// server config
-	server := server.NewServer(mgr.GetConfig())
+	server := mgr.GetServer()
+
+	// requests are counted and timed by route template
+	server.Router.Use(mgr.GetMetrics().Middleware)
+
+	// every request gets a request id, used by the logs and the audit log
+	server.Router.Use(mgr.GetMiddleware().InitLog)
+
+	// probes of the orchestrator, readiness fails once shutdown starts
+	server.Router.Handle("/healthz", mgr.GetHealth().Liveness()).Methods("GET")
+	server.Router.Handle("/readyz", mgr.GetHealth().Readiness()).Methods("GET")
+
+	if mgr.GetConfig().MetricsEnabled {
+		server.Router.Handle("/metrics", mgr.GetMetrics().Handler()).Methods("GET")
+	}
 
 	// start routes
 	orderRoutes.NewRoutes(server.Router, mgr)
 	customerRoutes.NewRoutes(server.Router, mgr)
+	productRoutes.NewRoutes(server.Router, mgr)
 	userRoutes.NewRoutes(server.Router, mgr)
+	auditRoutes.NewRoutes(server.Router, mgr)
+	webhookRoutes.NewRoutes(server.Router, mgr)
 	// end routes
 
 	server.RegisterRouter(server.Router)
 
-	return server.ListenAndServe()
+	// started in order and stopped in reverse on SIGTERM or SIGINT: readiness
+	// fails first, then requests in flight drain, then the relay and the
+	// webhook dispatcher stop, before the database is closed and the spans
+	// flushed
+	lc := mgr.GetLifecycle()
+	lc.Append(manager.WorkerHook("webhook dispatcher", webhookUsecase.NewDispatcher(mgr).Run))
+	lc.Append(manager.WorkerHook("outbox relay", newRelay(mgr).Run))
+	lc.Append(manager.Hook{
+		Name: "http server",
+		Start: func(context.Context) error {
+			return server.Start(lc.Fail)
+		},
+		Stop: server.Shutdown,
+	})
+	lc.Append(manager.Hook{Name: "health", Stop: func(ctx context.Context) error {
+		mgr.GetHealth().ShuttingDown()
+
+		// keep serving until the load balancers saw readiness fail
+		delay := time.Duration(mgr.GetConfig().ShutdownReadinessDelay) * time.Second
+		if delay <= 0 {
+			delay = defaultReadinessDelay
+		}
+
+		select {
+		case <-time.After(delay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
+
+	return lc.Run(context.Background())
+}
+
+// newRelay publishes to the webhook subscriptions, to the outbox webhook
+// when one is configured and to the log when asked to or when there is no
+// outbox webhook.
+func newRelay(mgr manager.Manager) *outbox.Relay {
+	cfg := mgr.GetConfig()
+
+	sinks := []outbox.Sink{webhookUsecase.NewSink(mgr)}
+	if cfg.OutboxWebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(mgr.GetHttp(), cfg.OutboxWebhookURL))
+	}
+	if cfg.OutboxLogSink || cfg.OutboxWebhookURL == "" {
+		sinks = append(sinks, outbox.NewLogSink(mgr.GetLog()))
+	}
+
+	return outbox.NewRelay(cfg, mgr.GetOutbox(), mgr.GetLog(), sinks...)
 }
 
 func main() {
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	customerRepository "github.com/ahsansandiah/dpo-test/api/customer/repository"
+	orderRepository "github.com/ahsansandiah/dpo-test/api/order/repository"
+	productRepository "github.com/ahsansandiah/dpo-test/api/product/repository"
+	userRepository "github.com/ahsansandiah/dpo-test/api/user/repository"
+	"github.com/ahsansandiah/dpo-test/packages/manager"
+)
+
+// defaultRetentionDays is used when SOFT_DELETE_RETENTION_DAYS is not set.
+const defaultRetentionDays = 30
+
+func run() error {
+	mgr, err := manager.NewInit()
+	if err != nil {
+		return err
+	}
+
+	defer shutdownTelemetry(mgr)
+
+	retentionDays := mgr.GetConfig().SoftDeleteRetentionDays
+	if retentionDays <= 0 {
+		retentionDays = defaultRetentionDays
+	}
+	flag.IntVar(&retentionDays, "retention-days", retentionDays, "purge records soft deleted more than this many days ago")
+	flag.Parse()
+
+	if retentionDays <= 0 {
+		return fmt.Errorf("retention-days must be positive")
+	}
+
+	ctx := context.Background()
+	before := time.Now().AddDate(0, 0, -retentionDays)
+
+	// orders go first, customers and products are only purged once no order
+	// refers to them anymore
+	purges := []struct {
+		name  string
+		purge func(ctx context.Context, before time.Time) (int64, error)
+	}{
+		{"orders", orderRepository.NewOrderRepository(mgr).Purge},
+		{"customers", customerRepository.NewCustomerRepository(mgr).Purge},
+		{"products", productRepository.NewProductRepository(mgr).Purge},
+		{"users", userRepository.NewUserRepository(mgr).Purge},
+	}
+
+	for _, p := range purges {
+		purged, err := p.purge(ctx, before)
+		if err != nil {
+			return fmt.Errorf("purging %s: %w", p.name, err)
+		}
+
+		fmt.Printf("purged %d %s deleted before %s\n", purged, p.name, before.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// shutdownTelemetry flushes the spans still buffered.
+func shutdownTelemetry(mgr manager.Manager) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := mgr.GetTelemetry().Shutdown(ctx); err != nil {
+		mgr.GetLog().ErrorLog(ctx, err)
+	}
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,25 +1,47 @@
 package errorHelper
 
-import "errors"
-
 var (
-	ErrorDataNotfound = errors.New("data not found")
+	ErrorDataNotfound = NotFound("data_not_found", "data not found")
+
+	// Error request
+	ErrorInvalidBody = BadRequest("invalid_body", "request body is invalid")
+	ErrorInvalidId   = BadRequest("invalid_id", "id is invalid")
+
+	// Error pagination
+	ErrorInvalidCursor = BadRequest("invalid_cursor", "cursor is invalid")
+	ErrorInvalidSortBy = Validation("invalid_sort_by", "sort by must be created_at or id")
+
+	// Error soft delete
+	ErrorInvalidDeletedScope = Validation("deleted_scope_invalid", "include deleted and only deleted must be true or false")
+	ErrorDeletedForbidden    = Forbidden("deleted_forbidden", "deleted records are only visible to admins")
 
 	// Error customer module
-	ErrorFullNameIsRequired    = errors.New("full name is required")
-	ErrorAddressIsRequired     = errors.New("address is required")
-	ErrorPhoneNumberIsRequired = errors.New("phone number is required")
-	ErrorEmailIsRequired       = errors.New("email is required")
+	ErrorCustomerNotFound = NotFound("customer_not_found", "customer not found")
+	ErrorCustomerInactive = Conflict("customer_inactive", "customer is inactive")
+	ErrorCustomerDeleted  = Conflict("customer_deleted", "customer is deleted, restore the customer first")
+	ErrorInvalidIsActive  = Validation("is_active_invalid", "is active must be true or false")
 
 	// Error order module
-	ErrorCustomerIdRequired   = errors.New("customer is required")
-	ErrorOrderDateRequired    = errors.New("order date is required")
-	ErrorAmountIsRequired     = errors.New("amount is required")
-	ErrorOrderItemsIsRequired = errors.New("order items is required")
+	ErrorOrderStatusInvalid  = Validation("status_invalid", "status is invalid")
+	ErrorOrderStatusConflict = Conflict("order_status_conflict", "order status transition is not allowed")
+	ErrorOrderTaxRateInvalid = Internal("order tax rate is invalid", nil)
+	ErrorOrderNotEditable    = Conflict("order_not_editable", "order items can only be changed while the order is Pending or Confirmed")
+	ErrorOrderNotFound       = NotFound("order_not_found", "order not found")
+
+	// Error product module
+	ErrorStockIsInvalid    = Validation("stock_invalid", "stock can not be negative")
+	ErrorSkuAlreadyExists  = Conflict("sku_already_exists", "sku already exists")
+	ErrorProductNotFound   = NotFound("product_not_found", "product not found")
+	ErrorInsufficientStock = Conflict("insufficient_stock", "insufficient stock")
 
 	// Error user module
-	ErrorUsernameIsRequired        = errors.New("User name is required")
-	ErrorPasswordIsRequired        = errors.New("Password is required")
-	ErrorPasswordConfirmIsRequired = errors.New("Password confirm is required")
-	ErrorPasswordNotMatch          = errors.New("Password not match")
+	ErrorInvalidCredentials  = Unauthorized("invalid_credentials", "Username or password does not match")
+	ErrorUserNotFound        = NotFound("user_not_found", "user not found")
+	ErrorRefreshTokenInvalid = Unauthorized("refresh_token_invalid", "Refresh token is invalid or has expired")
+	ErrorRefreshTokenReused  = Unauthorized("refresh_token_reused", "Refresh token has already been used")
+
+	// Error webhook module
+	ErrorWebhookNotFound         = NotFound("webhook_not_found", "webhook subscription not found")
+	ErrorWebhookDeliveryNotFound = NotFound("webhook_delivery_not_found", "webhook delivery not found")
+	ErrorWebhookNotFailed        = Conflict("webhook_delivery_not_failed", "only failed webhook deliveries can be redelivered")
 )
@@ -0,0 +1,100 @@
+package errorHelper
+
+import "errors"
+
+// Kind classifies an error by what the caller did wrong, if anything.
+type Kind string
+
+const (
+	KindBadRequest      Kind = "bad_request"
+	KindValidation      Kind = "validation"
+	KindNotFound        Kind = "not_found"
+	KindConflict        Kind = "conflict"
+	KindUnauthorized    Kind = "unauthorized"
+	KindForbidden       Kind = "forbidden"
+	KindTooManyRequests Kind = "too_many_requests"
+	KindInternal        Kind = "internal"
+)
+
+// kindedError is implemented by errors that know their kind.
+type kindedError interface {
+	ErrorKind() Kind
+}
+
+// Error is an error with a kind and a machine readable code.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) ErrorCode() string {
+	return e.Code
+}
+
+func (e *Error) ErrorKind() Kind {
+	return e.Kind
+}
+
+func BadRequest(code, message string) error {
+	return &Error{Kind: KindBadRequest, Code: code, Message: message}
+}
+
+func Validation(code, message string) error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+func NotFound(code, message string) error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Unauthorized(code, message string) error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+func TooManyRequests(code, message string) error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message}
+}
+
+// Internal describes a failure of the service, err is kept for logging.
+func Internal(message string, err error) error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: message, Err: err}
+}
+
+// Wrap returns err unchanged when it already has a kind and otherwise turns
+// it into an internal error with the given message.
+func Wrap(err error, message string) error {
+	var kinded kindedError
+	if errors.As(err, &kinded) {
+		return err
+	}
+
+	return Internal(message, err)
+}
+
+// KindOf returns the kind of err, errors without one are internal.
+func KindOf(err error) Kind {
+	var kinded kindedError
+	if errors.As(err, &kinded) {
+		return kinded.ErrorKind()
+	}
+
+	return KindInternal
+}
@@ -0,0 +1,25 @@
+package errorHelper
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, KindNotFound, KindOf(ErrorOrderNotFound))
+	assert.Equal(t, KindConflict, KindOf(fmt.Errorf("%w for product A", ErrorInsufficientStock)))
+	assert.Equal(t, KindValidation, KindOf(&ValidationError{}))
+	assert.Equal(t, KindInternal, KindOf(sql.ErrConnDone))
+}
+
+func TestWrapKeepsKindedErrors(t *testing.T) {
+	assert.Equal(t, ErrorCustomerNotFound, Wrap(ErrorCustomerNotFound, "Error fetching customer details"))
+
+	err := Wrap(sql.ErrConnDone, "Error fetching customer details")
+	assert.Equal(t, KindInternal, KindOf(err))
+	assert.Equal(t, "Error fetching customer details", err.Error())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+}
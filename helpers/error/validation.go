@@ -0,0 +1,50 @@
+package errorHelper
+
+import "strings"
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects field level errors of one request.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) ErrorCode() string {
+	return "validation_failed"
+}
+
+func (e *ValidationError) ErrorKind() Kind {
+	return KindValidation
+}
+
+func (e *ValidationError) ErrorDetails() interface{} {
+	return e.Fields
+}
+
+// Add records an error for the given field and the rule it broke.
+func (e *ValidationError) Add(field, rule, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Err returns the collected errors, or nil when every field was valid.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -1,20 +1,207 @@
 package paginateHelper
 
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+)
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByID        = "id"
+
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Cursor is the keyset position of a row: the value of the sort column and
+// the row id as a tie breaker. Backward cursors page towards newer rows.
 type Cursor struct {
-	ID     string // Example: Cursor ID or token
-	Offset int    // Example: Offset for fetching next set of data
+	SortBy   string `json:"s"`
+	Key      string `json:"k,omitempty"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+type Paginate struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// NewCursor builds the cursor of a row for the given sort column.
+func NewCursor(sortBy string, createdAt time.Time, ID int64) Cursor {
+	cursor := Cursor{
+		SortBy: sortBy,
+		ID:     ID,
+	}
+	if sortBy == SortByCreatedAt {
+		cursor.Key = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return cursor
+}
+
+// ValidateSortBy returns the sort column to use, defaulting to created_at.
+func ValidateSortBy(sortBy string) (string, error) {
+	switch sortBy {
+	case "":
+		return SortByCreatedAt, nil
+	case SortByCreatedAt, SortByID:
+		return sortBy, nil
+	}
+
+	return "", errorHelper.ErrorInvalidSortBy
+}
+
+// ValidateLimit clamps limit into the range accepted by list endpoints.
+func ValidateLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return limit
+}
+
+// EncodeCursor serializes the cursor into an opaque, signed base64 string.
+func EncodeCursor(cursor Cursor, secret string) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	signature := base64.RawURLEncoding.EncodeToString(sign(encoded, secret))
+
+	return encoded + "." + signature, nil
 }
 
-func ParseCursor(cursorStr string) (*Cursor, error) {
-	// Parse cursor string into Cursor struct
-	// Example: Splitting cursorStr or decoding from base64
-	// Example: Extracting ID and Offset from cursorStr
+// ParseCursor verifies the signature of a cursor produced by EncodeCursor and
+// decodes it.
+func ParseCursor(cursorStr string, secret string) (*Cursor, error) {
+	parts := strings.Split(cursorStr, ".")
+	if len(parts) != 2 {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
+
+	if !hmac.Equal(signature, sign(parts[0], secret)) {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
 
-	// For simplicity, let's assume cursorStr is just an ID
-	cursor := &Cursor{
-		ID:     cursorStr,
-		Offset: 0, // Initial offset can be adjusted based on needs
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(payload, cursor); err != nil {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
+
+	if _, err := ValidateSortBy(cursor.SortBy); err != nil || cursor.SortBy == "" {
+		return nil, errorHelper.ErrorInvalidCursor
+	}
+
+	if cursor.SortBy == SortByCreatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Key); err != nil {
+			return nil, errorHelper.ErrorInvalidCursor
+		}
 	}
 
 	return cursor, nil
 }
+
+// Condition returns the keyset WHERE fragment, prefixed with AND, selecting
+// the rows after the cursor for the table alias.
+func (c *Cursor) Condition(alias string) (string, []interface{}) {
+	op := "<"
+	if c.Backward {
+		op = ">"
+	}
+
+	if c.SortBy == SortByID {
+		return fmt.Sprintf(" AND %s.id %s ?", alias, op), []interface{}{c.ID}
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339Nano, c.Key)
+	condition := fmt.Sprintf(" AND (%[1]s.created_at %[2]s ? OR (%[1]s.created_at = ? AND %[1]s.id %[2]s ?))", alias, op)
+
+	return condition, []interface{}{createdAt, createdAt, c.ID}
+}
+
+// OrderBy returns the ORDER BY clause for the sort column. Rows are listed
+// newest first; backward pages are read in reverse and flipped by the caller.
+func OrderBy(alias string, sortBy string, backward bool) string {
+	direction := "DESC"
+	if backward {
+		direction = "ASC"
+	}
+
+	if sortBy == SortByID {
+		return fmt.Sprintf(" ORDER BY %s.id %s", alias, direction)
+	}
+
+	return fmt.Sprintf(" ORDER BY %[1]s.created_at %[2]s, %[1]s.id %[2]s", alias, direction)
+}
+
+// NewPaginate builds the next and previous cursors of a page. current is the
+// cursor the page was requested with, hasMore reports whether more rows exist
+// beyond the page in the requested direction, and first and last are the
+// cursors of the first and last rows of the page in display order.
+func NewPaginate(secret string, current *Cursor, hasMore bool, first, last *Cursor) (*Paginate, error) {
+	paginate := &Paginate{}
+	if first == nil || last == nil {
+		return paginate, nil
+	}
+
+	backward := current != nil && current.Backward
+	hasNext := hasMore
+	hasPrev := current != nil
+	if backward {
+		hasNext = true
+		hasPrev = hasMore
+	}
+
+	if hasNext {
+		next := *last
+		next.Backward = false
+		nextCursor, err := EncodeCursor(next, secret)
+		if err != nil {
+			return nil, err
+		}
+		paginate.NextCursor = nextCursor
+	}
+
+	if hasPrev {
+		prev := *first
+		prev.Backward = true
+		prevCursor, err := EncodeCursor(prev, secret)
+		if err != nil {
+			return nil, err
+		}
+		paginate.PrevCursor = prevCursor
+	}
+
+	return paginate, nil
+}
+
+func sign(payload string, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package paginateHelper
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeAndParseCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 7, 4, 18, 53, 31, 0, time.UTC)
+	cursor := NewCursor(SortByCreatedAt, createdAt, 42)
+
+	encoded, err := EncodeCursor(cursor, "secret")
+	assert.NoError(t, err)
+
+	parsed, err := ParseCursor(encoded, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, *parsed)
+}
+
+func TestParseCursorRejectsTamperedCursor(t *testing.T) {
+	encoded, err := EncodeCursor(NewCursor(SortByID, time.Time{}, 42), "secret")
+	assert.NoError(t, err)
+
+	_, err = ParseCursor(encoded, "another-secret")
+	assert.ErrorIs(t, err, errorHelper.ErrorInvalidCursor)
+
+	parts := strings.Split(encoded, ".")
+	forged, err := EncodeCursor(NewCursor(SortByID, time.Time{}, 1), "attacker")
+	assert.NoError(t, err)
+	_, err = ParseCursor(strings.Split(forged, ".")[0]+"."+parts[1], "secret")
+	assert.ErrorIs(t, err, errorHelper.ErrorInvalidCursor)
+
+	_, err = ParseCursor("not-a-cursor", "secret")
+	assert.ErrorIs(t, err, errorHelper.ErrorInvalidCursor)
+}
+
+func TestNewPaginateFirstPage(t *testing.T) {
+	first := NewCursor(SortByID, time.Time{}, 20)
+	last := NewCursor(SortByID, time.Time{}, 11)
+
+	paginate, err := NewPaginate("secret", nil, true, &first, &last)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paginate.NextCursor)
+	assert.Empty(t, paginate.PrevCursor)
+
+	next, err := ParseCursor(paginate.NextCursor, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), next.ID)
+	assert.False(t, next.Backward)
+}
+
+func TestNewPaginateBackwardPage(t *testing.T) {
+	current := NewCursor(SortByID, time.Time{}, 10)
+	current.Backward = true
+	first := NewCursor(SortByID, time.Time{}, 20)
+	last := NewCursor(SortByID, time.Time{}, 11)
+
+	paginate, err := NewPaginate("secret", &current, false, &first, &last)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paginate.NextCursor)
+	assert.Empty(t, paginate.PrevCursor)
+}
+
+func TestCursorCondition(t *testing.T) {
+	cursor := NewCursor(SortByID, time.Time{}, 7)
+	condition, args := cursor.Condition("o")
+	assert.Equal(t, " AND o.id < ?", condition)
+	assert.Equal(t, []interface{}{int64(7)}, args)
+
+	cursor.Backward = true
+	condition, _ = cursor.Condition("o")
+	assert.Equal(t, " AND o.id > ?", condition)
+	assert.Equal(t, " ORDER BY o.id ASC", OrderBy("o", SortByID, true))
+}
+
+func TestValidateLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, ValidateLimit(0))
+	assert.Equal(t, MaxLimit, ValidateLimit(MaxLimit+1))
+	assert.Equal(t, 25, ValidateLimit(25))
+}
@@ -0,0 +1,69 @@
+package softDeleteHelper
+
+import (
+	"fmt"
+	"strconv"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+)
+
+// Scope selects rows of a soft deletable table by their deleted_at.
+type Scope string
+
+const (
+	// ScopeActive leaves deleted rows out, it is the default of every query.
+	ScopeActive Scope = ""
+	// ScopeWithDeleted returns deleted rows next to the active ones.
+	ScopeWithDeleted Scope = "with_deleted"
+	// ScopeOnlyDeleted returns the deleted rows only, the trash.
+	ScopeOnlyDeleted Scope = "only_deleted"
+)
+
+// ParseScope reads the include_deleted and only_deleted query parameters,
+// only_deleted wins when both are set.
+func ParseScope(includeDeleted, onlyDeleted string) (Scope, error) {
+	include, err := parseFlag(includeDeleted)
+	if err != nil {
+		return ScopeActive, err
+	}
+
+	only, err := parseFlag(onlyDeleted)
+	if err != nil {
+		return ScopeActive, err
+	}
+
+	switch {
+	case only:
+		return ScopeOnlyDeleted, nil
+	case include:
+		return ScopeWithDeleted, nil
+	}
+
+	return ScopeActive, nil
+}
+
+func parseFlag(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errorHelper.ErrorInvalidDeletedScope
+	}
+
+	return flag, nil
+}
+
+// Condition returns the WHERE fragment, prefixed with AND, of the scope for
+// the table alias.
+func (s Scope) Condition(alias string) string {
+	switch s {
+	case ScopeWithDeleted:
+		return ""
+	case ScopeOnlyDeleted:
+		return fmt.Sprintf(" AND %s.deleted_at IS NOT NULL", alias)
+	}
+
+	return fmt.Sprintf(" AND %s.deleted_at IS NULL", alias)
+}
@@ -0,0 +1,31 @@
+package softDeleteHelper
+
+import (
+	"testing"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScope(t *testing.T) {
+	scope, err := ParseScope("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeActive, scope)
+
+	scope, err = ParseScope("true", "")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeWithDeleted, scope)
+
+	scope, err = ParseScope("true", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeOnlyDeleted, scope)
+
+	_, err = ParseScope("yes", "")
+	assert.ErrorIs(t, err, errorHelper.ErrorInvalidDeletedScope)
+}
+
+func TestScopeCondition(t *testing.T) {
+	assert.Equal(t, " AND c.deleted_at IS NULL", ScopeActive.Condition("c"))
+	assert.Equal(t, "", ScopeWithDeleted.Condition("c"))
+	assert.Equal(t, " AND c.deleted_at IS NOT NULL", ScopeOnlyDeleted.Condition("c"))
+}
@@ -0,0 +1,58 @@
+package traceHelper
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ahsansandiah/dpo-test"
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// spanNames caches the span name of every caller, keyed by program counter.
+var spanNames sync.Map
+
+// Start opens a span named after the calling method, such as
+// usecase.CustomerUsecase.GetAll, the caller ends it:
+//
+//	ctx, span := traceHelper.Start(ctx)
+//	defer span.End()
+func Start(ctx context.Context, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	name := "unknown"
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		name = spanName(pc)
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+func spanName(pc uintptr) string {
+	if name, ok := spanNames.Load(pc); ok {
+		return name.(string)
+	}
+
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = FuncName(fn.Name())
+	}
+	spanNames.Store(pc, name)
+
+	return name
+}
+
+// FuncName shortens a qualified function name to its package directory,
+// receiver and method, github.com/x/api/customer/usecase.(*CustomerUsecase).GetAll
+// becomes usecase.CustomerUsecase.GetAll. Closures are named after their
+// enclosing method.
+func FuncName(qualified string) string {
+	name := qualified[strings.LastIndex(qualified, "/")+1:]
+	name = closureSuffix.ReplaceAllString(name, "")
+
+	return strings.NewReplacer("(*", "", "(", "", ")", "").Replace(name)
+}
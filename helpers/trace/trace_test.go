@@ -1,6 +1,7 @@
 package traceHelper
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -8,6 +9,10 @@ import (
 	"runtime"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func TestErrorTraceCorrectFileAndLineNumber(t *testing.T) {
@@ -49,3 +54,29 @@ func TestErrorTraceExceedsStackSize(t *testing.T) {
 		t.Errorf("Expected a non-empty function string, but got an empty string")
 	}
 }
+
+func TestFuncName(t *testing.T) {
+	assert.Equal(t, "usecase.CustomerUsecase.GetAll", FuncName("github.com/ahsansandiah/dpo-test/api/customer/usecase.(*CustomerUsecase).GetAll"))
+	assert.Equal(t, "mysql.Options.Connect", FuncName("github.com/ahsansandiah/dpo-test/packages/storage/mysql.(Options).Connect"))
+	assert.Equal(t, "main.run", FuncName("main.run"))
+	assert.Equal(t, "handler.Customer.GetAll", FuncName("github.com/ahsansandiah/dpo-test/api/customer/delivery/handler.(*Customer).GetAll.func1"))
+}
+
+type tracedUsecase struct{}
+
+func (u *tracedUsecase) GetAll(ctx context.Context) {
+	_, span := Start(ctx)
+	span.End()
+}
+
+func TestStartNamesSpanAfterCaller(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	new(tracedUsecase).GetAll(context.Background())
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "trace.tracedUsecase.GetAll", spans[0].Name())
+}
@@ -0,0 +1,143 @@
+package validationHelper
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+)
+
+// Rules reported back to the client in the field errors.
+const (
+	RuleRequired = "required"
+	RuleEmail    = "email"
+	RulePhone    = "e164"
+	RuleMin      = "min"
+	RuleMax      = "max"
+	RuleLength   = "length"
+	RulePassword = "password"
+	RuleMatch    = "match"
+	RuleExists   = "exists"
+	RuleUnique   = "unique"
+	RuleOneOf    = "one_of"
+	RuleDecimal  = "decimal"
+	RuleURL      = "url"
+)
+
+// PasswordMinLength is the shortest password Password accepts.
+const PasswordMinLength = 8
+
+// e164 matches a phone number in E.164 format, e.g. +6281234567890.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// Validator collects every field error of a request instead of stopping at
+// the first one. Format rules skip empty values, those are left to Required.
+type Validator struct {
+	errors errorHelper.ValidationError
+}
+
+func New() *Validator {
+	return new(Validator)
+}
+
+// Add records an error of a custom rule.
+func (v *Validator) Add(field, rule, message string) {
+	v.errors.Add(field, rule, message)
+}
+
+// Check records an error when ok is false.
+func (v *Validator) Check(ok bool, field, rule, message string) bool {
+	if !ok {
+		v.Add(field, rule, message)
+	}
+
+	return ok
+}
+
+// Err returns a validation error listing every violation, or nil.
+func (v *Validator) Err() error {
+	return v.errors.Err()
+}
+
+func (v *Validator) Required(field, value string) bool {
+	return v.Check(value != "", field, RuleRequired, fmt.Sprintf("%s is required", field))
+}
+
+func (v *Validator) Email(field, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	// ParseAddress also accepts "Name <address>", only a bare address is valid
+	address, err := mail.ParseAddress(value)
+	return v.Check(err == nil && address.Address == value, field, RuleEmail, fmt.Sprintf("%s must be a valid email address", field))
+}
+
+func (v *Validator) Phone(field, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	return v.Check(e164.MatchString(value), field, RulePhone, fmt.Sprintf("%s must be in E.164 format, e.g. +6281234567890", field))
+}
+
+func (v *Validator) Min(field string, value, min int64) bool {
+	return v.Check(value >= min, field, RuleMin, fmt.Sprintf("%s must be at least %d", field, min))
+}
+
+func (v *Validator) Max(field string, value, max int64) bool {
+	return v.Check(value <= max, field, RuleMax, fmt.Sprintf("%s must be at most %d", field, max))
+}
+
+// URL checks value is an absolute http or https URL.
+func (v *Validator) URL(field, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(value)
+	valid := err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+	return v.Check(valid, field, RuleURL, fmt.Sprintf("%s must be an http or https URL", field))
+}
+
+// Length checks the number of characters of value is between min and max.
+func (v *Validator) Length(field, value string, min, max int) bool {
+	if value == "" {
+		return true
+	}
+
+	length := utf8.RuneCountInString(value)
+	return v.Check(length >= min && length <= max, field, RuleLength, fmt.Sprintf("%s must be between %d and %d characters", field, min, max))
+}
+
+// Password requires PasswordMinLength characters with an upper case letter, a
+// lower case letter and a digit.
+func (v *Validator) Password(field, value string) bool {
+	if value == "" {
+		return true
+	}
+
+	var upper, lower, digit bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+
+	strong := utf8.RuneCountInString(value) >= PasswordMinLength && upper && lower && digit
+	return v.Check(strong, field, RulePassword, fmt.Sprintf("%s must have at least %d characters with an upper case letter, a lower case letter and a digit", field, PasswordMinLength))
+}
+
+// Match checks value equals the value of the field it confirms.
+func (v *Validator) Match(field, value, otherField, other string) bool {
+	return v.Check(value == other, field, RuleMatch, fmt.Sprintf("%s must match %s", field, otherField))
+}
@@ -0,0 +1,63 @@
+package validationHelper
+
+import (
+	"errors"
+	"testing"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorCollectsEveryFieldError(t *testing.T) {
+	v := New()
+	v.Required("full_name", "")
+	v.Email("email", "john@")
+	v.Phone("phone_number", "081234567890")
+	v.Min("quantity", 0, 1)
+	v.Length("username", "jo", 3, 50)
+	v.URL("url", "ftp://example.com")
+
+	var validationErr *errorHelper.ValidationError
+	assert.True(t, errors.As(v.Err(), &validationErr))
+
+	rules := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		rules = append(rules, field.Field+":"+field.Rule)
+	}
+	assert.Equal(t, []string{"full_name:required", "email:email", "phone_number:e164", "quantity:min", "username:length", "url:url"}, rules)
+}
+
+func TestValidatorAcceptsValidValues(t *testing.T) {
+	v := New()
+	v.Required("full_name", "John Doe")
+	v.Email("email", "john@example.com")
+	v.Phone("phone_number", "+6281234567890")
+	v.Max("quantity", 10, 10)
+	v.Password("password", "Secret123")
+	v.Match("password_confirm", "Secret123", "password", "Secret123")
+	v.URL("url", "https://example.com/webhooks")
+
+	assert.NoError(t, v.Err())
+}
+
+func TestFormatRulesSkipEmptyValues(t *testing.T) {
+	v := New()
+	v.Email("email", "")
+	v.Phone("phone_number", "")
+	v.Password("password", "")
+
+	assert.NoError(t, v.Err())
+}
+
+func TestEmailRejectsDisplayName(t *testing.T) {
+	v := New()
+
+	assert.False(t, v.Email("email", "John <john@example.com>"))
+}
+
+func TestPasswordStrength(t *testing.T) {
+	for _, password := range []string{"Short1", "alllowercase1", "ALLUPPERCASE1", "NoDigitsHere"} {
+		v := New()
+		assert.False(t, v.Password("password", password), password)
+	}
+}
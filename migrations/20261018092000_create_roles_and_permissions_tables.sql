@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE role_permissions (
+    role_id INT NOT NULL,
+    permission_id INT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE user_roles (
+    user_id INT NOT NULL,
+    role_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access to every resource'),
+    ('sales', 'Manage customers and orders'),
+    ('viewer', 'Read only access');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name) VALUES
+    ('customers:read'),
+    ('customers:write'),
+    ('customers:delete'),
+    ('orders:read'),
+    ('orders:write'),
+    ('orders:delete'),
+    ('orders:transition');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+   OR (r.name = 'sales' AND p.name IN ('customers:read', 'customers:write', 'orders:read', 'orders:write', 'orders:transition'))
+   OR (r.name = 'viewer' AND p.name IN ('customers:read', 'orders:read'));
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Existing users could do everything before roles existed, keep it that way
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u CROSS JOIN roles r WHERE r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE role_permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE roles;
+-- +goose StatementEnd
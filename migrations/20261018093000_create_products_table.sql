@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE products (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    unit_price DECIMAL(10, 2) NOT NULL,
+    stock INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL,
+    CHECK (unit_price > 0),
+    CHECK (stock >= 0)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- Items created before the catalog existed keep a NULL product
+ALTER TABLE order_items
+    ADD COLUMN product_id INT NULL AFTER order_id,
+    ADD CONSTRAINT fk_order_items_product FOREIGN KEY (product_id) REFERENCES products(id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name) VALUES
+    ('products:read'),
+    ('products:write'),
+    ('products:delete');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE p.name IN ('products:read', 'products:write', 'products:delete')
+  AND (r.name = 'admin' OR p.name = 'products:read');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('products:read', 'products:write', 'products:delete');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_items DROP FOREIGN KEY fk_order_items_product;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_items DROP COLUMN product_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE products;
+-- +goose StatementEnd
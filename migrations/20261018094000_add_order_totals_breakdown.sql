@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Orders created before the breakdown existed had neither discounts nor tax
+ALTER TABLE orders
+    ADD COLUMN subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER status,
+    ADD COLUMN discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER subtotal,
+    ADD COLUMN tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER discount_amount;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE orders SET subtotal = total_amount;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE order_items
+    ADD COLUMN discount DECIMAL(10, 2) NOT NULL DEFAULT 0 AFTER price;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_items DROP COLUMN discount;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN tax_amount,
+    DROP COLUMN discount_amount,
+    DROP COLUMN subtotal;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL,
+    user_id INT NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INT DEFAULT NULL,
+    response_body MEDIUMBLOB DEFAULT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_idempotency_keys_user_key (user_id, idempotency_key),
+    INDEX idx_idempotency_keys_expires_at (expires_at)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
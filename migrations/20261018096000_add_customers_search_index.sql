@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the ngram parser indexes every two character sequence, which lets partial
+-- and misspelled names still find the customer
+ALTER TABLE customers ADD FULLTEXT INDEX ft_customers_search (full_name, email, phone_number) WITH PARSER ngram;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE customers DROP INDEX ft_customers_search;
+-- +goose StatementEnd
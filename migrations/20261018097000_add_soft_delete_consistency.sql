@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- the purge command looks deleted rows up by deleted_at
+ALTER TABLE customers ADD INDEX idx_customers_deleted_at (deleted_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders ADD INDEX idx_orders_deleted_at (deleted_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('customers:restore', 'List and restore deleted customers'),
+    ('orders:restore', 'List and restore deleted orders');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name IN ('customers:restore', 'orders:restore');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name IN ('customers:restore', 'orders:restore');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE orders DROP INDEX idx_orders_deleted_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE customers DROP INDEX idx_customers_deleted_at;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN deleted_at;
+-- +goose StatementEnd
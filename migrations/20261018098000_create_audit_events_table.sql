@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_events (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor_id INT DEFAULT NULL,
+    actor_username VARCHAR(255) DEFAULT NULL,
+    request_id VARCHAR(36) DEFAULT NULL,
+    before_data JSON DEFAULT NULL,
+    after_data JSON DEFAULT NULL,
+    changes JSON DEFAULT NULL,
+    created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX idx_audit_events_entity (entity_type, entity_id, id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Read the audit log');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name = 'audit:read';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'audit:read';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE audit_events;
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+package denylistAuth
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+type Denylist interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+type Options struct {
+	db  *sql.DB
+	log log.Log
+}
+
+func NewDenylist(db *sql.DB, lg log.Log) Denylist {
+	opt := new(Options)
+	opt.db = db
+	opt.log = lg
+
+	return opt
+}
+
+// Revoke denylists an access token id until the token itself expires.
+func (o *Options) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	_, err := o.db.ExecContext(ctx, "INSERT INTO revoked_access_tokens (jti, expires_at) VALUES (?, ?) ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)", tokenID, expiresAt)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	// Entries past their expiry can never match a valid token again
+	_, err = o.db.ExecContext(ctx, "DELETE FROM revoked_access_tokens WHERE expires_at < ?", time.Now())
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+	}
+
+	return nil
+}
+
+func (o *Options) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int
+	err := o.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM revoked_access_tokens WHERE jti = ?", tokenID).Scan(&count)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -1,16 +1,24 @@
 package jwtAuth
 
 import (
+	"time"
+
 	"github.com/dgrijalva/jwt-go"
 )
 
 type JwtData struct {
-	UserID    int64  `json:"user_id"`
-	Reference string `json:"reference"`
+	UserID      int64     `json:"user_id"`
+	Reference   string    `json:"reference"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	TokenID     string    `json:"token_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
 }
 
 type JwtPayload struct {
-	Reference string `json:"reference"`
-	UserID    int64  `json:"ui"`
+	Reference   string   `json:"reference"`
+	UserID      int64    `json:"ui"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
 	jwt.StandardClaims
 }
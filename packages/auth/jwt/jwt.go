@@ -1,40 +1,49 @@
 package jwtAuth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 type Jwt interface {
 	GenerateToken(data *JwtData) (string, *time.Time, error)
-	ExtractJwtToken(token string) (*JwtData, error)
+	GenerateRefreshToken() (string, *time.Time, error)
 	VerifyAccessToken(token string, secretKey string) (*JwtData, error)
 }
 
 type Options struct {
-	secretKey           string
-	accessTokenDuration int
+	secretKey            string
+	accessTokenDuration  int
+	refreshTokenDuration int
 }
 
 func NewJwt(cfg *config.Config) Jwt {
 	opt := new(Options)
 	opt.secretKey = cfg.JwtSecretKey
 	opt.accessTokenDuration = cfg.JwtAccessTokenDuration
+	opt.refreshTokenDuration = cfg.JwtRefreshTokenDuration
 
 	return opt
 }
 
 func (o *Options) GenerateToken(data *JwtData) (string, *time.Time, error) {
 	jwtPayload := &JwtPayload{
-		Reference: data.Reference,
-		UserID:    data.UserID,
+		Reference:   data.Reference,
+		UserID:      data.UserID,
+		Roles:       data.Roles,
+		Permissions: data.Permissions,
 	}
 
 	expiredTime := time.Now().Local().Add(time.Second * time.Duration(o.accessTokenDuration))
+	jwtPayload.StandardClaims.Id = uuid.New().String()
 	jwtPayload.StandardClaims.ExpiresAt = expiredTime.Unix()
 	jwtPayload.StandardClaims.NotBefore = jwt.TimeFunc().Local().Unix()
 	acToken := jwt.NewWithClaims(jwt.SigningMethodHS512, jwtPayload)
@@ -47,21 +56,17 @@ func (o *Options) GenerateToken(data *JwtData) (string, *time.Time, error) {
 	return accessToken, &expiredTime, nil
 }
 
-func (o *Options) ExtractJwtToken(token string) (*JwtData, error) {
-	parsedToken, err := jwt.Parse(token, nil)
-	if strings.Contains(err.Error(), "invalid number of segments") {
-		return nil, err
+// GenerateRefreshToken returns a random opaque refresh token and its expiry.
+// Only its HashToken digest should be persisted.
+func (o *Options) GenerateRefreshToken() (string, *time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
 	}
 
-	claims, _ := parsedToken.Claims.(jwt.MapClaims)
-
-	userId := int64(claims["ui"].(float64))
-	jwtData := &JwtData{
-		Reference: claims["reference"].(string),
-		UserID:    userId,
-	}
+	expiredTime := time.Now().Local().Add(time.Second * time.Duration(o.refreshTokenDuration))
 
-	return jwtData, nil
+	return base64.RawURLEncoding.EncodeToString(raw), &expiredTime, nil
 }
 
 func (o *Options) VerifyAccessToken(token string, secretKey string) (*JwtData, error) {
@@ -81,9 +86,49 @@ func (o *Options) VerifyAccessToken(token string, secretKey string) (*JwtData, e
 		return nil, err
 	}
 
+	reference, _ := claims["reference"].(string)
 	jwtData := &JwtData{
-		Reference: claims["reference"].(string),
+		Reference: reference,
+	}
+
+	if userID, ok := claims["ui"].(float64); ok {
+		jwtData.UserID = int64(userID)
+	}
+
+	jwtData.Roles = stringClaims(claims["roles"])
+	jwtData.Permissions = stringClaims(claims["permissions"])
+
+	if tokenID, ok := claims["jti"].(string); ok {
+		jwtData.TokenID = tokenID
+	}
+
+	if expiresAt, ok := claims["exp"].(float64); ok {
+		jwtData.ExpiresAt = time.Unix(int64(expiresAt), 0)
 	}
 
 	return jwtData, nil
 }
+
+func stringClaims(claim interface{}) []string {
+	values, ok := claim.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			result = append(result, str)
+		}
+	}
+
+	return result
+}
+
+// HashToken returns the hex encoded SHA-256 digest stored in place of a
+// refresh token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -1,12 +1,43 @@
 package middlewareAuth
 
-import "errors"
+import errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
 
 var (
-	ErrorAuthHeaderEmpty       = errors.New("authorization header is empty")
-	ErrorAuthNotHaveBearer     = errors.New("authorization header doesn't have bearer format")
-	ErrorAuthNotHaveToken      = errors.New("authorization header doesn't have access token value")
-	ErrorAccessTokenEmpty      = errors.New("failed getting data from access token")
-	ErrorDataFromContext       = errors.New("failed getting data from context")
-	ErrorInvalidTokenOrExpired = errors.New("token is invalid or has expired")
+	ErrorAuthHeaderEmpty       = errorHelper.Unauthorized("auth_header_empty", "authorization header is empty")
+	ErrorAuthNotHaveBearer     = errorHelper.Unauthorized("auth_header_not_bearer", "authorization header doesn't have bearer format")
+	ErrorAuthNotHaveToken      = errorHelper.Unauthorized("auth_token_missing", "authorization header doesn't have access token value")
+	ErrorAccessTokenEmpty      = errorHelper.Unauthorized("access_token_empty", "failed getting data from access token")
+	ErrorDataFromContext       = errorHelper.Unauthorized("principal_missing", "failed getting data from context")
+	ErrorInvalidTokenOrExpired = errorHelper.Unauthorized("token_invalid", "token is invalid or has expired")
+	ErrorTokenRevoked          = errorHelper.Unauthorized("token_revoked", "token has been revoked")
+
+	ErrorIdempotencyKeyTooLong    = errorHelper.BadRequest("idempotency_key_too_long", "idempotency key must not be longer than 255 characters")
+	ErrorIdempotencyKeyReused     = errorHelper.Conflict("idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrorIdempotencyKeyInProgress = errorHelper.Conflict("idempotency_key_in_progress", "a request with this idempotency key is still being processed")
+
+	ErrorRateLimited = errorHelper.TooManyRequests("rate_limited", "too many requests, retry after the time in the Retry-After header")
 )
+
+// PermissionError is returned when the principal lacks permissions required
+// by a route.
+type PermissionError struct {
+	Missing []string
+}
+
+func (e *PermissionError) Error() string {
+	return "you do not have permission to access this resource"
+}
+
+func (e *PermissionError) ErrorKind() errorHelper.Kind {
+	return errorHelper.KindForbidden
+}
+
+func (e *PermissionError) ErrorCode() string {
+	return "forbidden"
+}
+
+func (e *PermissionError) ErrorDetails() interface{} {
+	return map[string]interface{}{
+		"missing_permissions": e.Missing,
+	}
+}
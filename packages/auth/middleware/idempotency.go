@@ -0,0 +1,146 @@
+package middlewareAuth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	idempotencyDatabase "github.com/ahsansandiah/dpo-test/packages/storage/idempotency"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength matches the idempotency_keys column size
+	maxIdempotencyKeyLength = 255
+	// defaultIdempotencyTTL is used when no TTL is configured
+	defaultIdempotencyTTL = 24 * time.Hour
+	// settleTimeout bounds storing the outcome of a request
+	settleTimeout = 5 * time.Second
+)
+
+// responseRecorder keeps a copy of what a handler writes so it can be stored.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first successful response is stored and replayed for repeats
+// of the same request, a different request reusing the key is rejected. It
+// must run after CheckToken, keys are scoped to the authenticated user.
+func (o *Options) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		key := r.Header.Get(HeaderIdempotencyKey)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			o.json.ErrorResponse(w, r, ErrorIdempotencyKeyTooLong)
+			return
+		}
+
+		bodyBytes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			o.json.ErrorResponse(w, r, errorHelper.ErrorInvalidBody)
+			return
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(bodyBytes)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		userID := principalAuth.UserID(ctx)
+
+		record, err := o.idempotency.Get(ctx, userID, key)
+		if err != nil {
+			o.json.ErrorResponse(w, r, errorHelper.Internal("Failed to process idempotency key", err))
+			return
+		}
+
+		if record == nil {
+			record = &idempotencyDatabase.Record{
+				Key:         key,
+				UserID:      userID,
+				RequestHash: requestHash,
+				ExpiresAt:   time.Now().Add(o.idempotencyTTL),
+			}
+
+			err = o.idempotency.Reserve(ctx, record)
+			if errors.Is(err, idempotencyDatabase.ErrorKeyExists) {
+				o.json.ErrorResponse(w, r, ErrorIdempotencyKeyInProgress)
+				return
+			}
+			if err != nil {
+				o.json.ErrorResponse(w, r, errorHelper.Internal("Failed to process idempotency key", err))
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+
+			// The client may have timed out and cancelled ctx, the key must be
+			// settled all the same or its retries are in progress until it
+			// expires
+			settleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+			defer cancel()
+
+			// Only successful responses are kept, failed requests may be retried
+			if recorder.statusCode >= http.StatusOK && recorder.statusCode < http.StatusMultipleChoices {
+				err = o.idempotency.Complete(settleCtx, userID, key, recorder.statusCode, recorder.body.Bytes())
+			} else {
+				err = o.idempotency.Release(settleCtx, userID, key)
+			}
+			if err != nil {
+				o.log.Error(ctx, "failed to settle idempotency key", logger.Fields{"idempotency_key": key, "error": err.Error()})
+			}
+			return
+		}
+
+		if record.RequestHash != requestHash {
+			o.json.ErrorResponse(w, r, ErrorIdempotencyKeyReused)
+			return
+		}
+
+		if record.StatusCode == 0 {
+			o.json.ErrorResponse(w, r, ErrorIdempotencyKeyInProgress)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(HeaderIdempotencyReplayed, "true")
+		w.WriteHeader(record.StatusCode)
+		w.Write(record.ResponseBody)
+	})
+}
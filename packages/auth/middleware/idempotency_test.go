@@ -0,0 +1,106 @@
+package middlewareAuth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsonResponse "github.com/ahsansandiah/dpo-test/packages/json"
+	idempotencyDatabase "github.com/ahsansandiah/dpo-test/packages/storage/idempotency"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotency struct {
+	records map[string]*idempotencyDatabase.Record
+}
+
+func (m *memoryIdempotency) Purge(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryIdempotency) Get(ctx context.Context, userID int64, key string) (*idempotencyDatabase.Record, error) {
+	return m.records[key], nil
+}
+
+func (m *memoryIdempotency) Reserve(ctx context.Context, record *idempotencyDatabase.Record) error {
+	if _, exists := m.records[record.Key]; exists {
+		return idempotencyDatabase.ErrorKeyExists
+	}
+	m.records[record.Key] = record
+
+	return nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.records[key].StatusCode = statusCode
+	m.records[key].ResponseBody = body
+
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, userID int64, key string) error {
+	delete(m.records, key)
+
+	return nil
+}
+
+func TestIdempotencyReplaysAndRejectsDifferentBody(t *testing.T) {
+	json := jsonResponse.NewMockJson(gomock.NewController(t))
+	middleware := &Options{
+		json:           json,
+		idempotency:    &memoryIdempotency{records: map[string]*idempotencyDatabase.Record{}},
+		idempotencyTTL: time.Hour,
+	}
+
+	calls := 0
+	handler := middleware.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"data":{"id":1}}`))
+	}))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		r.Header.Set(HeaderIdempotencyKey, "key-1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	first := send(`{"customer_id":1}`)
+	replay := send(`{"customer_id":1}`)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get(HeaderIdempotencyReplayed))
+
+	json.EXPECT().ErrorResponse(gomock.Any(), gomock.Any(), ErrorIdempotencyKeyReused)
+	send(`{"customer_id":2}`)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencySettlesKeyOfTimedOutClient(t *testing.T) {
+	store := &memoryIdempotency{records: map[string]*idempotencyDatabase.Record{}}
+	middleware := &Options{idempotency: store, idempotencyTTL: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := middleware.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client gives up while the order is created
+		cancel()
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"customer_id":1}`)).WithContext(ctx)
+	r.Header.Set(HeaderIdempotencyKey, "key-1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, http.StatusCreated, store.records["key-1"].StatusCode)
+}
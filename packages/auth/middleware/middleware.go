@@ -8,29 +8,49 @@ import (
 	"strings"
 	"time"
 
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	denylistAuth "github.com/ahsansandiah/dpo-test/packages/auth/denylist"
 	jwtAuth "github.com/ahsansandiah/dpo-test/packages/auth/jwt"
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	jsonResponse "github.com/ahsansandiah/dpo-test/packages/json"
 	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/ratelimit"
+	idempotencyDatabase "github.com/ahsansandiah/dpo-test/packages/storage/idempotency"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 type Middleware interface {
 	InitLog(next http.Handler) http.Handler
+	Idempotency(next http.Handler) http.Handler
 	CheckToken(next http.Handler) http.Handler
+	RequirePermission(permissions ...string) func(next http.Handler) http.Handler
+	RateLimit(route string) func(next http.Handler) http.Handler
 	GetTokenInHeader(r *http.Request) (string, error)
 }
 
 type Options struct {
-	jwt       jwtAuth.Jwt
-	secretKey string
-	log       logger.Log
-	json      jsonResponse.Json
+	jwt            jwtAuth.Jwt
+	denylist       denylistAuth.Denylist
+	idempotency    idempotencyDatabase.Idempotency
+	idempotencyTTL time.Duration
+	limiter        ratelimit.Limiter
+	secretKey      string
+	log            logger.Log
+	json           jsonResponse.Json
 }
 
-func NewMiddleware(cfg *config.Config, lg logger.Log, jsonRes jsonResponse.Json) Middleware {
+func NewMiddleware(cfg *config.Config, lg logger.Log, jsonRes jsonResponse.Json, denylist denylistAuth.Denylist, idempotency idempotencyDatabase.Idempotency, limiter ratelimit.Limiter) Middleware {
 	opt := new(Options)
 	opt.jwt = jwtAuth.NewJwt(cfg)
+	opt.denylist = denylist
+	opt.idempotency = idempotency
+	opt.idempotencyTTL = time.Duration(cfg.IdempotencyKeyTTL) * time.Second
+	if opt.idempotencyTTL <= 0 {
+		opt.idempotencyTTL = defaultIdempotencyTTL
+	}
+	opt.limiter = limiter
 	opt.secretKey = cfg.JwtSecretKey
 	opt.log = lg
 	opt.json = jsonRes
@@ -52,6 +72,11 @@ func (o *Options) InitLog(next http.Handler) http.Handler {
 		ctx := context.WithValue(r.Context(), config.ContextKey("body"), bodyBytes)
 		ctx = context.WithValue(ctx, config.ContextKey("startTime"), tnow)
 		ctx = context.WithValue(ctx, config.ContextKey("id"), id)
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				ctx = context.WithValue(ctx, config.ContextKey("route"), template)
+			}
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -83,27 +108,69 @@ func (o *Options) CheckToken(next http.Handler) http.Handler {
 
 		accessToken, err := o.GetTokenInHeader(r)
 		if err != nil {
-			o.json.ErrorResponse(w, r, http.StatusUnauthorized, err)
+			o.json.ErrorResponse(w, r, err)
 			return
 		}
 
-		_, err = o.jwt.ExtractJwtToken(accessToken)
+		jwtData, err := o.jwt.VerifyAccessToken(accessToken, o.secretKey)
 		if err != nil {
-			o.json.ErrorResponse(w, r, http.StatusUnauthorized, ErrorInvalidTokenOrExpired)
+			o.json.ErrorResponse(w, r, ErrorInvalidTokenOrExpired)
 			return
 		}
 
-		jwtData, err := o.jwt.VerifyAccessToken(accessToken, o.secretKey)
+		if jwtData == nil {
+			o.json.ErrorResponse(w, r, ErrorAccessTokenEmpty)
+			return
+		}
+
+		revoked, err := o.denylist.IsRevoked(ctx, jwtData.TokenID)
 		if err != nil {
-			o.json.ErrorResponse(w, r, http.StatusUnauthorized, ErrorInvalidTokenOrExpired)
+			o.json.ErrorResponse(w, r, errorHelper.Internal("Failed to check token", err))
 			return
 		}
 
-		if jwtData == nil {
-			o.json.ErrorResponse(w, r, http.StatusUnauthorized, ErrorAccessTokenEmpty)
+		if revoked {
+			o.json.ErrorResponse(w, r, ErrorTokenRevoked)
 			return
 		}
 
+		ctx = principalAuth.NewContext(ctx, &principalAuth.Principal{
+			UserID:      jwtData.UserID,
+			Username:    jwtData.Reference,
+			Roles:       jwtData.Roles,
+			Permissions: jwtData.Permissions,
+			TokenID:     jwtData.TokenID,
+			ExpiresAt:   jwtData.ExpiresAt,
+		})
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequirePermission allows the request only when the principal placed in the
+// context by CheckToken holds every one of the permissions.
+func (o *Options) RequirePermission(permissions ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := principalAuth.FromContext(r.Context())
+			if !ok {
+				o.json.ErrorResponse(w, r, ErrorDataFromContext)
+				return
+			}
+
+			var missing []string
+			for _, permission := range permissions {
+				if !principal.HasPermission(permission) {
+					missing = append(missing, permission)
+				}
+			}
+
+			if len(missing) > 0 {
+				o.json.ErrorResponse(w, r, &PermissionError{Missing: missing})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
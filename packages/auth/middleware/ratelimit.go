@@ -0,0 +1,51 @@
+package middlewareAuth
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// RateLimit throttles the requests of each client to the limit of route,
+// answering 429 once its bucket is empty. Behind CheckToken clients are
+// counted by user, otherwise by IP.
+func (o *Options) RateLimit(route string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := o.limiter.Allow(r, route)
+			if err != nil {
+				// a shared store being down must not take the api down with it
+				o.log.ErrorLog(r.Context(), err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if result.Limit > 0 {
+				w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+				w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+				w.Header().Set(HeaderRateLimitReset, ceilSeconds(result.Reset))
+			}
+
+			if !result.Allowed {
+				w.Header().Set(HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+				o.json.ErrorResponse(w, r, ErrorRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, headers can't say less than one.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
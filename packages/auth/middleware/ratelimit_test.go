@@ -0,0 +1,44 @@
+package middlewareAuth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	jsonResponse "github.com/ahsansandiah/dpo-test/packages/json"
+	"github.com/ahsansandiah/dpo-test/packages/ratelimit"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitAnswersTooManyRequests(t *testing.T) {
+	json := jsonResponse.NewMockJson(gomock.NewController(t))
+	limiter, err := ratelimit.NewLimiter(&config.Config{RateLimitRoutes: "auth=2/1m"}, ratelimit.NewMemoryStore())
+	assert.NoError(t, err)
+	middleware := &Options{json: json, limiter: limiter}
+
+	calls := 0
+	handler := middleware.RateLimit("auth")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
+
+		return w
+	}
+
+	first := send()
+	assert.Equal(t, "2", first.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", first.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", first.Header().Get(HeaderRateLimitReset))
+	send()
+
+	json.EXPECT().ErrorResponse(gomock.Any(), gomock.Any(), ErrorRateLimited)
+	limited := send()
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "0", limited.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", limited.Header().Get(HeaderRetryAfter))
+}
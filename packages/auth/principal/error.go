@@ -0,0 +1,7 @@
+package principalAuth
+
+import errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+
+var (
+	ErrorPrincipalNotFound = errorHelper.Unauthorized("principal_missing", "authenticated user not found in context")
+)
@@ -0,0 +1,95 @@
+package principalAuth
+
+import (
+	"context"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+)
+
+// Principal is the authenticated user of a request, as verified from its
+// access token by the CheckToken middleware.
+type Principal struct {
+	UserID      int64     `json:"user_id"`
+	Username    string    `json:"username"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	TokenID     string    `json:"token_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// HasPermission reports whether the principal was granted the permission
+// through any of its roles.
+func (p *Principal) HasPermission(permission string) bool {
+	for _, granted := range p.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, config.ContextKey("principal"), principal)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(config.ContextKey("principal")).(*Principal)
+	if !ok || principal == nil {
+		return nil, false
+	}
+
+	return principal, true
+}
+
+// Authenticated returns the principal of the request or
+// ErrorPrincipalNotFound when the route is not behind CheckToken.
+func Authenticated(ctx context.Context) (*Principal, error) {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrorPrincipalNotFound
+	}
+
+	return principal, nil
+}
+
+func UserID(ctx context.Context) int64 {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.UserID
+	}
+
+	return 0
+}
+
+func Username(ctx context.Context) string {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.Username
+	}
+
+	return ""
+}
+
+func Roles(ctx context.Context) []string {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.Roles
+	}
+
+	return nil
+}
+
+func TokenID(ctx context.Context) string {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.TokenID
+	}
+
+	return ""
+}
+
+func HasPermission(ctx context.Context, permission string) bool {
+	if principal, ok := FromContext(ctx); ok {
+		return principal.HasPermission(permission)
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package httpClient
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a consecutive failure circuit breaker for one host. It opens
+// after threshold failures, rejects calls for the cooldown and then lets a
+// single probe through: a successful probe closes it, a failed one opens it
+// again.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may go out, ErrCircuitOpen when it may not.
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+
+	b.probing = true
+	return nil
+}
+
+// record reports the outcome of an allowed call.
+func (b *breaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		b.open = false
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.open = true
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// release gives back an allowed call that ended without an outcome, such as
+// a cancelled context, so a pending probe does not block the host forever.
+func (b *breaker) release() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
@@ -0,0 +1,59 @@
+package httpClient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.record(true)
+	assert.NoError(t, b.allow())
+	b.record(true)
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen)
+}
+
+func TestBreakerProbesAfterCooldown(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+	b.record(true)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen, "only one probe at a time")
+
+	b.record(true)
+	assert.ErrorIs(t, b.allow(), ErrCircuitOpen, "a failed probe opens the circuit again")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	b.record(false)
+	assert.NoError(t, b.allow())
+	assert.NoError(t, b.allow())
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(1, time.Minute)
+	b.now = func() time.Time { return now }
+	b.record(true)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.allow())
+	b.release()
+	assert.NoError(t, b.allow())
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(-1, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.record(true)
+	}
+	assert.NoError(t, b.allow())
+}
@@ -1,7 +1,26 @@
 package httpClient
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrCodeNot200 = errors.New("code not 200")
+	ErrCodeNot200       = errors.New("code not 200")
+	ErrCircuitOpen      = errors.New("circuit open")
+	ErrResponseTooLarge = errors.New("response too large")
 )
+
+// StatusError is returned for responses with a non 2xx status code, it
+// matches ErrCodeNot200 with errors.Is.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrCodeNot200.Error(), e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrCodeNot200
+}
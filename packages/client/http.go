@@ -4,76 +4,336 @@ import (
 	"bytes"
 	"context"
 	"errors"
-	"io/ioutil"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	HeaderRequestID = "X-Request-Id"
+
+	defaultTimeout          = 10
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 2 * time.Second
+	defaultBreakerFailures  = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	defaultMaxResponseBytes = 10 << 20
 )
 
 type Http interface {
 	Connect()
 	CallURL(ctx context.Context, method, url string, header map[string]string, rawData []byte) ([]byte, error)
+	Do(ctx context.Context, req Request) (*Response, error)
+}
+
+// Request is an outbound call. Idempotent marks a request that is safe to
+// retry even though its method is not, Retry overrides the configured policy.
+type Request struct {
+	Method     string
+	URL        string
+	Header     map[string]string
+	Body       []byte
+	Idempotent bool
+	Retry      *RetryPolicy
+}
+
+// Response is the last response received, it is returned together with a
+// StatusError for non 2xx codes.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
 }
 
 type Options struct {
-	timeout int
-	http    *http.Client
-	log     log.Log
+	timeout          int
+	retry            RetryPolicy
+	breakerFailures  int
+	breakerCooldown  time.Duration
+	maxResponseBytes int64
+	allowedIP        func(ip net.IP) bool
+	noRedirects      bool
+	http             *http.Client
+	log              log.Log
+	metrics          metrics.Metrics
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
 }
 
-func NewHttp(cfg *config.Config, log log.Log) Http {
+// ErrAddressNotAllowed is returned for a call to an address the client was
+// told to stay away from with WithAllowedIP.
+var ErrAddressNotAllowed = errors.New("address is not allowed")
+
+// Option tunes a client made by NewHttp.
+type Option func(*Options)
+
+// WithAllowedIP makes the client connect only to the addresses allowed
+// accepts. The address is checked when the connection is made, after name
+// resolution, so a name can not be rebound to a refused address in between.
+// Proxies from the environment are ignored, the client connects directly.
+func WithAllowedIP(allowed func(ip net.IP) bool) Option {
+	return func(o *Options) {
+		o.allowedIP = allowed
+	}
+}
+
+// WithoutRedirects returns redirect responses to the caller, as a
+// StatusError, instead of following them.
+func WithoutRedirects() Option {
+	return func(o *Options) {
+		o.noRedirects = true
+	}
+}
+
+func NewHttp(cfg *config.Config, log log.Log, mt metrics.Metrics, options ...Option) Http {
 	opt := new(Options)
-	opt.timeout = cfg.ServerHTTPReadTimeout
+	opt.timeout = cfg.HttpClientTimeout
+	if opt.timeout <= 0 {
+		opt.timeout = defaultTimeout
+	}
+	opt.retry = RetryPolicy{
+		MaxAttempts: cfg.HttpClientRetryMaxAttempts,
+		BaseDelay:   time.Duration(cfg.HttpClientRetryBaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.HttpClientRetryMaxDelayMs) * time.Millisecond,
+	}
+	if opt.retry.MaxAttempts <= 0 {
+		opt.retry.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if opt.retry.BaseDelay <= 0 {
+		opt.retry.BaseDelay = defaultRetryBaseDelay
+	}
+	if opt.retry.MaxDelay <= 0 {
+		opt.retry.MaxDelay = defaultRetryMaxDelay
+	}
+	opt.breakerFailures = cfg.HttpClientBreakerFailures
+	if opt.breakerFailures == 0 {
+		opt.breakerFailures = defaultBreakerFailures
+	}
+	opt.breakerCooldown = time.Duration(cfg.HttpClientBreakerCooldown) * time.Second
+	if opt.breakerCooldown <= 0 {
+		opt.breakerCooldown = defaultBreakerCooldown
+	}
+	opt.maxResponseBytes = cfg.HttpClientMaxResponseBytes
+	if opt.maxResponseBytes <= 0 {
+		opt.maxResponseBytes = defaultMaxResponseBytes
+	}
+	opt.breakers = make(map[string]*breaker)
 	opt.log = log
+	opt.metrics = mt
+	for _, option := range options {
+		option(opt)
+	}
 	return opt
 }
 
 func (o *Options) Connect() {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.allowedIP != nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: o.checkAddress}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+
+	// the transport opens a client span and sends its traceparent
 	httpClient := &http.Client{
-		Timeout: time.Duration(o.timeout) * time.Second,
+		Timeout:   time.Duration(o.timeout) * time.Second,
+		Transport: otelhttp.NewTransport(transport),
+	}
+	if o.noRedirects {
+		httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
 	}
 
 	o.http = httpClient
 }
 
+// checkAddress refuses to connect to an address allowedIP does not accept.
+func (o *Options) checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !o.allowedIP(ip) {
+		return errPermanent{fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)}
+	}
+
+	return nil
+}
+
+// CallURL sends a request and returns the response body, any non 2xx status
+// is returned as a StatusError along with the body.
 func (o *Options) CallURL(ctx context.Context, method, url string, header map[string]string, rawData []byte) ([]byte, error) {
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(rawData))
+	res, err := o.Do(ctx, Request{Method: method, URL: url, Header: header, Body: rawData})
+	if res == nil {
+		return nil, err
+	}
+
+	return res.Body, err
+}
+
+// Do sends a request, retrying idempotent ones on network errors and
+// temporary failures. Calls to a host whose circuit is open fail with
+// ErrCircuitOpen without going out.
+func (o *Options) Do(ctx context.Context, req Request) (*Response, error) {
+	policy := o.retry
+	if req.Retry != nil {
+		policy = *req.Retry
+	}
+
+	attempts := 1
+	if isIdempotent(req) && policy.MaxAttempts > 1 {
+		attempts = policy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := o.send(ctx, req)
+		if err == nil || attempt >= attempts || !shouldRetry(err) || ctx.Err() != nil {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt)
+		if res != nil {
+			if retryAfter, ok := policy.retryAfter(res.Header); ok {
+				wait = retryAfter
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, err
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes a single attempt.
+func (o *Options) send(ctx context.Context, req Request) (*Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bytes.NewReader(req.Body))
 	if err != nil {
-		err := errors.New("[CallURL-1] Failed To Prepare Request Client HTTP")
+		err := fmt.Errorf("[CallURL-1] Failed To Prepare Request Client HTTP: %w", errPermanent{err})
 		o.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 
-	if len(header) > 0 {
-		for key, value := range header {
-			req.Header.Add(key, value)
-		}
+	for key, value := range req.Header {
+		httpReq.Header.Add(key, value)
+	}
+	if id, ok := ctx.Value(config.ContextKey("id")).(string); ok && id != "" && httpReq.Header.Get(HeaderRequestID) == "" {
+		httpReq.Header.Set(HeaderRequestID, id)
+	}
+
+	host := httpReq.URL.Host
+	breaker := o.breaker(host)
+	if err := breaker.allow(); err != nil {
+		o.metrics.ObserveHttpCall(host, req.Method, "circuit_open", 0)
+		err := fmt.Errorf("[CallURL-2] Failed To Request Client HTTP %s: %w", host, err)
+		o.log.ErrorLog(ctx, err)
+		return nil, err
 	}
 
-	res, err := o.http.Do(req)
+	start := time.Now()
+	res, err := o.http.Do(httpReq)
 	if err != nil {
-		err := errors.New("[CallURL-2] Failed To Request Client HTTP")
+		o.metrics.ObserveHttpCall(host, req.Method, "error", time.Since(start))
+		o.fail(ctx, breaker)
+		err := fmt.Errorf("[CallURL-2] Failed To Request Client HTTP: %w", err)
 		o.log.ErrorLog(ctx, err)
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	response := &Response{StatusCode: res.StatusCode, Header: res.Header}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, o.maxResponseBytes+1))
 	if err != nil {
-		err := errors.New("[CallURL-3] Failed To Read Result Client HTTP")
+		o.metrics.ObserveHttpCall(host, req.Method, "error", time.Since(start))
+		o.fail(ctx, breaker)
+		err := fmt.Errorf("[CallURL-3] Failed To Read Result Client HTTP: %w", err)
 		o.log.ErrorLog(ctx, err)
-		return nil, err
+		return response, err
+	}
+
+	o.metrics.ObserveHttpCall(host, req.Method, strconv.Itoa(res.StatusCode), time.Since(start))
+	breaker.record(res.StatusCode >= http.StatusInternalServerError)
+
+	if int64(len(body)) > o.maxResponseBytes {
+		err := fmt.Errorf("[CallURL-3] Failed To Read Result Client HTTP: %w", errPermanent{ErrResponseTooLarge})
+		o.log.ErrorLog(ctx, err)
+		return response, err
 	}
+	response.Body = body
 
-	o.log.HttpLog(ctx, req, rawData, body)
+	o.log.HttpLog(ctx, httpReq, req.Body, body)
 
-	if res.StatusCode != 200 {
-		err := errors.New("[CallURL-4] Error Status Code Not 200")
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := errors.New("[CallURL-4] Error Status Code Not 2xx")
 		o.log.ErrorLog(ctx, err)
-		return body, ErrCodeNot200
+		return response, &StatusError{StatusCode: res.StatusCode}
+	}
+
+	return response, nil
+}
+
+// breaker returns the circuit breaker of a host.
+func (o *Options) breaker(host string) *breaker {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	b, ok := o.breakers[host]
+	if !ok {
+		b = newBreaker(o.breakerFailures, o.breakerCooldown)
+		o.breakers[host] = b
+	}
+
+	return b
+}
+
+// fail records a failed call, unless it failed because the caller gave up.
+func (o *Options) fail(ctx context.Context, b *breaker) {
+	if ctx.Err() != nil {
+		b.release()
+		return
+	}
+
+	b.record(true)
+}
+
+// errPermanent marks a failure another attempt cannot fix.
+type errPermanent struct {
+	err error
+}
+
+func (e errPermanent) Error() string {
+	return e.err.Error()
+}
+
+func (e errPermanent) Unwrap() error {
+	return e.err
+}
+
+// shouldRetry tells whether a failed attempt may succeed when repeated.
+func shouldRetry(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return retryableStatus[statusErr.StatusCode]
 	}
 
-	return body, nil
+	var permanent errPermanent
+	return !errors.As(err, &permanent) && !errors.Is(err, ErrCircuitOpen)
 }
@@ -49,6 +49,21 @@ func (mr *MockHttpMockRecorder) CallURL(ctx, method, url, header, rawData interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallURL", reflect.TypeOf((*MockHttp)(nil).CallURL), ctx, method, url, header, rawData)
 }
 
+// Do mocks base method.
+func (m *MockHttp) Do(ctx context.Context, req Request) (*Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, req)
+	ret0, _ := ret[0].(*Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockHttpMockRecorder) Do(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockHttp)(nil).Do), ctx, req)
+}
+
 // Connect mocks base method.
 func (m *MockHttp) Connect() {
 	m.ctrl.T.Helper()
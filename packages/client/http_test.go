@@ -0,0 +1,192 @@
+package httpClient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHttp(t *testing.T, cfg *config.Config) Http {
+	lg := log.NewMockLog(gomock.NewController(t))
+	lg.EXPECT().ErrorLog(gomock.Any(), gomock.Any()).AnyTimes()
+	lg.EXPECT().HttpLog(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	cfg.HttpClientTimeout = 5
+	cfg.HttpClientRetryBaseDelayMs = 1
+	cfg.HttpClientRetryMaxDelayMs = 5
+	mt, err := metrics.NewMetrics(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewHttp(cfg, lg, mt)
+	client.Connect()
+	return client
+}
+
+func TestDoAcceptsAny2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/things/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	res, err := newTestHttp(t, &config.Config{}).Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "/things/1", res.Header.Get("Location"))
+}
+
+func TestDoRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	res, err := newTestHttp(t, &config.Config{}).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(res.Body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestDoDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	res, err := newTestHttp(t, &config.Config{}).Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL})
+	assert.ErrorIs(t, err, ErrCodeNot200)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err = newTestHttp(t, &config.Config{}).Do(context.Background(), Request{
+		Method: http.MethodPost,
+		URL:    server.URL,
+		Header: map[string]string{"Idempotency-Key": "abc"},
+	})
+	assert.ErrorIs(t, err, ErrCodeNot200)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls), "an idempotency key makes a POST retryable")
+}
+
+func TestDoOpensCircuitPerHost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestHttp(t, &config.Config{HttpClientBreakerFailures: 2, HttpClientRetryMaxAttempts: 1})
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+		assert.ErrorIs(t, err, ErrCodeNot200)
+	}
+
+	_, err := client.Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDoLimitsResponseSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 11)))
+	}))
+	defer server.Close()
+
+	client := newTestHttp(t, &config.Config{HttpClientMaxResponseBytes: 10})
+	res, err := client.Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Nil(t, res.Body)
+}
+
+func TestDoForwardsRequestID(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderRequestID)
+	}))
+	defer server.Close()
+
+	ctx := context.WithValue(context.Background(), config.ContextKey("id"), "req-1")
+	_, err := newTestHttp(t, &config.Config{}).CallURL(ctx, http.MethodGet, server.URL, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", got)
+}
+
+func TestDoStopsWhenContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := newTestHttp(t, &config.Config{}).Do(ctx, Request{Method: http.MethodGet, URL: server.URL})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBackoffStaysWithinBounds(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond}
+	for retry := 1; retry <= 40; retry++ {
+		wait := policy.backoff(retry)
+		assert.GreaterOrEqual(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, 40*time.Millisecond)
+	}
+	for i := 0; i < 20; i++ {
+		assert.LessOrEqual(t, policy.backoff(1), 10*time.Millisecond)
+	}
+}
+
+func TestRetryAfterReadsSecondsAndDates(t *testing.T) {
+	policy := RetryPolicy{MaxDelay: time.Minute}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	header := http.Header{}
+
+	header.Set("Retry-After", "3")
+	wait, ok := policy.retryAfterAt(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	header.Set("Retry-After", now.Add(20*time.Second).Format(http.TimeFormat))
+	wait, ok = policy.retryAfterAt(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, 20*time.Second, wait)
+
+	header.Set("Retry-After", now.Add(time.Hour).Format(http.TimeFormat))
+	wait, _ = policy.retryAfterAt(header, now)
+	assert.Equal(t, time.Minute, wait)
+
+	header.Set("Retry-After", now.Add(-time.Hour).Format(http.TimeFormat))
+	wait, ok = policy.retryAfterAt(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	header.Set("Retry-After", "soon")
+	_, ok = policy.retryAfterAt(header, now)
+	assert.False(t, ok)
+}
+
+func TestNewHttpHasADefaultTimeout(t *testing.T) {
+	client := NewHttp(&config.Config{ServerHTTPReadTimeout: 60}, nil, nil).(*Options)
+
+	assert.Equal(t, defaultTimeout, client.timeout)
+}
@@ -0,0 +1,106 @@
+package httpClient
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how idempotent requests are retried. Delays grow
+// exponentially from BaseDelay up to MaxDelay with full jitter.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatus are the responses worth another attempt, the server was
+// unavailable or asked to slow down.
+var retryableStatus = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// isIdempotent tells whether a request may be sent more than once.
+func isIdempotent(req Request) bool {
+	if req.Idempotent || idempotentMethods[req.Method] {
+		return true
+	}
+
+	for key := range req.Header {
+		if http.CanonicalHeaderKey(key) == "Idempotency-Key" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff is the wait before the given retry, counted from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	ceiling := p.MaxDelay
+	if shift := retry - 1; shift < 30 {
+		if d := p.BaseDelay << uint(shift); d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+
+	return time.Duration(jitterRand.Int63n(int64(ceiling) + 1))
+}
+
+// retryAfter reads a Retry-After header, in seconds or as an HTTP date,
+// capped at MaxDelay. A date in the past asks for no wait.
+func (p RetryPolicy) retryAfter(header http.Header) (time.Duration, bool) {
+	return p.retryAfterAt(header, time.Now())
+}
+
+func (p RetryPolicy) retryAfterAt(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		wait = time.Duration(seconds) * time.Second
+	} else {
+		date, err := http.ParseTime(value)
+		if err != nil {
+			return 0, false
+		}
+		if wait = date.Sub(now); wait < 0 {
+			wait = 0
+		}
+	}
+
+	if wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+
+	return wait, true
+}
@@ -12,14 +12,52 @@ type Config struct {
 	AppEnv                     string `mapstructure:"APP_ENV"`
 	AppTz                      string `mapstructure:"APP_TZ"`
 	AppIsDev                   bool
-	DatabaseDriver             string `mapstructure:"DATABASE_DRIVER"`
-	DatabaseDNS                string `mapstructure:"DATABASE_DNS"`
-	DatabaseMaxOpenConnections int    `mapstructure:"DATABASE_MAX_OPEN_CONNECTIONS"`
-	DatabaseMaxIdleConnections int    `mapstructure:"DATABASE_MAX_IDLE_CONNECTIONS"`
-	PortHttpServer             string `mapstructure:"PORT_HTTP_SERVER"`
-	ServerHTTPReadTimeout      int    `mapstructure:"SERVER_HTTP_READ_TIMEOUT"`
-	JwtSecretKey               string `mapstructure:"JWT_SECRET_KEY"`
-	JwtAccessTokenDuration     int    `mapstructure:"JWT_ACCESS_TOKEN_DURATION_SECONDS"`
+	LogLevel                   string  `mapstructure:"LOG_LEVEL"`
+	LogRedactKeys              string  `mapstructure:"LOG_REDACT_KEYS"`
+	TraceExporter              string  `mapstructure:"TRACE_EXPORTER"`
+	TraceFile                  string  `mapstructure:"TRACE_FILE"`
+	TraceOtlpEndpoint          string  `mapstructure:"TRACE_OTLP_ENDPOINT"`
+	TraceOtlpInsecure          bool    `mapstructure:"TRACE_OTLP_INSECURE"`
+	TraceSampleRatio           float64 `mapstructure:"TRACE_SAMPLE_RATIO"`
+	TraceServiceName           string  `mapstructure:"TRACE_SERVICE_NAME"`
+	MetricsEnabled             bool    `mapstructure:"METRICS_ENABLED"`
+	MetricsToken               string  `mapstructure:"METRICS_TOKEN"`
+	MetricsAllowedCIDRs        string  `mapstructure:"METRICS_ALLOWED_CIDRS"`
+	RateLimitDefault           string  `mapstructure:"RATE_LIMIT_DEFAULT"`
+	RateLimitRoutes            string  `mapstructure:"RATE_LIMIT_ROUTES"`
+	RateLimitTrustedProxies    string  `mapstructure:"RATE_LIMIT_TRUSTED_PROXIES"`
+	HealthCheckTimeoutMs       int     `mapstructure:"HEALTH_CHECK_TIMEOUT_MS"`
+	MigrationsDir              string  `mapstructure:"MIGRATIONS_DIR"`
+	DatabaseDriver             string  `mapstructure:"DATABASE_DRIVER"`
+	DatabaseDNS                string  `mapstructure:"DATABASE_DNS"`
+	DatabaseMaxOpenConnections int     `mapstructure:"DATABASE_MAX_OPEN_CONNECTIONS"`
+	DatabaseMaxIdleConnections int     `mapstructure:"DATABASE_MAX_IDLE_CONNECTIONS"`
+	PortHttpServer             string  `mapstructure:"PORT_HTTP_SERVER"`
+	ServerHTTPReadTimeout      int     `mapstructure:"SERVER_HTTP_READ_TIMEOUT"`
+	ServerHTTPWriteTimeout     int     `mapstructure:"SERVER_HTTP_WRITE_TIMEOUT"`
+	ServerHTTPIdleTimeout      int     `mapstructure:"SERVER_HTTP_IDLE_TIMEOUT"`
+	ShutdownDrainTimeout       int     `mapstructure:"SHUTDOWN_DRAIN_TIMEOUT_SECONDS"`
+	ShutdownReadinessDelay     int     `mapstructure:"SHUTDOWN_READINESS_DELAY_SECONDS"`
+	JwtSecretKey               string  `mapstructure:"JWT_SECRET_KEY"`
+	JwtAccessTokenDuration     int     `mapstructure:"JWT_ACCESS_TOKEN_DURATION_SECONDS"`
+	JwtRefreshTokenDuration    int     `mapstructure:"JWT_REFRESH_TOKEN_DURATION_SECONDS"`
+	PaginateCursorSecret       string  `mapstructure:"PAGINATE_CURSOR_SECRET"`
+	OrderTaxRate               string  `mapstructure:"ORDER_TAX_RATE"`
+	IdempotencyKeyTTL          int     `mapstructure:"IDEMPOTENCY_KEY_TTL_SECONDS"`
+	SoftDeleteRetentionDays    int     `mapstructure:"SOFT_DELETE_RETENTION_DAYS"`
+	OutboxPollIntervalMs       int     `mapstructure:"OUTBOX_POLL_INTERVAL_MS"`
+	OutboxBatchSize            int     `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxMaxAttempts          int     `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxWebhookURL           string  `mapstructure:"OUTBOX_WEBHOOK_URL"`
+	OutboxLogSink              bool    `mapstructure:"OUTBOX_LOG_SINK"`
+	WebhookRetryMaxElapsed     int     `mapstructure:"WEBHOOK_RETRY_MAX_ELAPSED_SECONDS"`
+	HttpClientTimeout          int     `mapstructure:"HTTP_CLIENT_TIMEOUT_SECONDS"`
+	HttpClientRetryMaxAttempts int     `mapstructure:"HTTP_CLIENT_RETRY_MAX_ATTEMPTS"`
+	HttpClientRetryBaseDelayMs int     `mapstructure:"HTTP_CLIENT_RETRY_BASE_DELAY_MS"`
+	HttpClientRetryMaxDelayMs  int     `mapstructure:"HTTP_CLIENT_RETRY_MAX_DELAY_MS"`
+	HttpClientBreakerFailures  int     `mapstructure:"HTTP_CLIENT_BREAKER_FAILURES"`
+	HttpClientBreakerCooldown  int     `mapstructure:"HTTP_CLIENT_BREAKER_COOLDOWN_SECONDS"`
+	HttpClientMaxResponseBytes int64   `mapstructure:"HTTP_CLIENT_MAX_RESPONSE_BYTES"`
 }
 
 func NewConfig() (*Config, error) {
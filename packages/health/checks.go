@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DatabaseCheck pings the database.
+func DatabaseCheck(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck fails while the goose version of the database is behind the
+// newest migration in dir.
+func MigrationCheck(db *sql.DB, dir string) Check {
+	return func(ctx context.Context) error {
+		expected, err := LatestMigration(dir)
+		if err != nil {
+			return err
+		}
+
+		current, err := databaseVersion(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		if current < expected {
+			return fmt.Errorf("database at migration %d, expected %d", current, expected)
+		}
+
+		return nil
+	}
+}
+
+// LatestMigration is the highest version among the goose files of dir.
+func LatestMigration(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		prefix := name
+		if i := strings.Index(name, "_"); i >= 0 {
+			prefix = name[:i]
+		}
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	return latest, nil
+}
+
+// databaseVersion reads the current version the way goose does: the newest
+// applied row whose version was not rolled back afterwards.
+func databaseVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return 0, fmt.Errorf("reading migration version: %w", err)
+	}
+	defer rows.Close()
+
+	rolledBack := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+
+		if !applied {
+			rolledBack[version] = true
+			continue
+		}
+		if !rolledBack[version] {
+			return version, nil
+		}
+	}
+
+	return 0, rows.Err()
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20261018099000_create_outbox_table.sql", "20261018100000_create_webhook_tables.sql", "README.md", "notes_1.sql"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+
+	version, err := LatestMigration(dir)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20261018100000), version)
+
+	_, err = LatestMigration(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestDatabaseVersionSkipsRolledBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// 3 was applied and rolled back, so the database is at 2
+	mock.ExpectQuery("SELECT version_id, is_applied FROM goose_db_version").
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}).
+			AddRow(3, false).
+			AddRow(3, true).
+			AddRow(2, true).
+			AddRow(1, true))
+
+	version, err := databaseVersion(context.Background(), db)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+}
+
+func TestMigrationCheckFailsWhenBehind(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "5_latest.sql"), nil, 0644))
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT version_id").WillReturnRows(sqlmock.NewRows([]string{"version_id", "is_applied"}).AddRow(4, true))
+
+	err = MigrationCheck(db, dir)(context.Background())
+
+	assert.EqualError(t, err, "database at migration 4, expected 5")
+}
@@ -0,0 +1,194 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+
+	defaultTimeout = 2 * time.Second
+)
+
+// Check reports whether a dependency is usable, it must return once ctx is
+// done.
+type Check func(ctx context.Context) error
+
+type Health interface {
+	Register(name string, check Check)
+	ShuttingDown()
+	Liveness() http.Handler
+	Readiness() http.Handler
+}
+
+type Options struct {
+	timeout      time.Duration
+	shuttingDown int32
+	log          log.Log
+	details      func(r *http.Request) bool
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// CheckResult is the outcome of one check, the error and the duration are
+// only shown to the callers allowed to see details.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+// Report is the body of both endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// NewHealth serves the errors and durations of the readiness checks only to
+// the requests details allows, everybody else sees whether each check
+// passed. Failing checks are logged with their error.
+func NewHealth(cfg *config.Config, lg log.Log, details func(r *http.Request) bool) Health {
+	opt := new(Options)
+	opt.log = lg
+	opt.details = details
+	opt.timeout = time.Duration(cfg.HealthCheckTimeoutMs) * time.Millisecond
+	if opt.timeout <= 0 {
+		opt.timeout = defaultTimeout
+	}
+	opt.checks = make(map[string]Check)
+
+	return opt
+}
+
+// Register adds a check to readiness, a later check of the same name
+// replaces the earlier one.
+func (o *Options) Register(name string, check Check) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.checks[name] = check
+}
+
+// ShuttingDown makes readiness fail from now on, so no new traffic is routed
+// to the instance while it drains.
+func (o *Options) ShuttingDown() {
+	atomic.StoreInt32(&o.shuttingDown, 1)
+}
+
+// Liveness answers as long as the process serves requests.
+func (o *Options) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, &Report{Status: StatusOK})
+	})
+}
+
+// Readiness runs every registered check and fails when one of them does.
+func (o *Options) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&o.shuttingDown) == 1 {
+			writeReport(w, http.StatusServiceUnavailable, &Report{Status: StatusShuttingDown})
+			return
+		}
+
+		report := o.run(r.Context())
+
+		statusCode := http.StatusOK
+		if report.Status != StatusOK {
+			statusCode = http.StatusServiceUnavailable
+			for name, result := range report.Checks {
+				if result.Status != StatusOK {
+					o.log.Error(r.Context(), "readiness check failed", log.Fields{"check": name, "error": result.Error, "duration_ms": result.DurationMs})
+				}
+			}
+		}
+
+		if o.details == nil || !o.details(r) {
+			for name, result := range report.Checks {
+				report.Checks[name] = CheckResult{Status: result.Status}
+			}
+		}
+		writeReport(w, statusCode, report)
+	})
+}
+
+// run executes the checks concurrently within the timeout.
+func (o *Options) run(ctx context.Context) *Report {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	o.mu.RLock()
+	names := make([]string, 0, len(o.checks))
+	for name := range o.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = o.checks[name]
+	}
+	o.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func runCheck(ctx context.Context, check Check) CheckResult {
+	start := time.Now()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func writeReport(w http.ResponseWriter, statusCode int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(t *testing.T, handler http.Handler) (int, Report) {
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("GET", "/readyz", nil))
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &report))
+	return res.Code, report
+}
+
+func newTestHealth(cfg *config.Config, details bool) Health {
+	cfg.LogLevel = "panic"
+	return NewHealth(cfg, log.NewLog(cfg), func(*http.Request) bool { return details })
+}
+
+func TestReadinessReportsEveryCheck(t *testing.T) {
+	hc := newTestHealth(&config.Config{}, true)
+	hc.Register("database", func(ctx context.Context) error { return nil })
+	hc.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	code, report := probe(t, hc.Readiness())
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	assert.Equal(t, "connection refused", report.Checks["cache"].Error)
+}
+
+func TestReadinessTimesOutSlowChecks(t *testing.T) {
+	hc := newTestHealth(&config.Config{HealthCheckTimeoutMs: 10}, true)
+	hc.Register("stuck", func(ctx context.Context) error {
+		select {}
+	})
+
+	code, report := probe(t, hc.Readiness())
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["stuck"].Error)
+}
+
+func TestReadinessHidesDetailsFromOtherCallers(t *testing.T) {
+	hc := newTestHealth(&config.Config{}, false)
+	hc.Register("database", func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:3306: connection refused") })
+
+	code, report := probe(t, hc.Readiness())
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, CheckResult{Status: StatusFail}, report.Checks["database"])
+}
+
+func TestReadinessFailsOnceShuttingDown(t *testing.T) {
+	hc := newTestHealth(&config.Config{}, false)
+	hc.Register("database", func(ctx context.Context) error { return nil })
+
+	code, _ := probe(t, hc.Readiness())
+	assert.Equal(t, http.StatusOK, code)
+
+	hc.ShuttingDown()
+
+	code, report := probe(t, hc.Readiness())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusShuttingDown, report.Status)
+
+	code, _ = probe(t, hc.Liveness())
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -6,6 +6,19 @@ type response struct {
 }
 type meta struct {
 	StatusCode int         `json:"status_code,omitempty"`
-	Code       string      `json:"code,omitempty"`
+	Code       string      `json:"error_code,omitempty"`
 	Message    interface{} `json:"message,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+	Errors     interface{} `json:"errors,omitempty"`
+}
+
+// codedError is implemented by errors carrying a machine readable code.
+type codedError interface {
+	ErrorCode() string
+}
+
+// detailedError is implemented by errors carrying structured details.
+type detailedError interface {
+	ErrorDetails() interface{}
 }
@@ -2,14 +2,24 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	errorHelper "github.com/ahsansandiah/dpo-test/helpers/error"
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
 	"github.com/ahsansandiah/dpo-test/packages/log"
 )
 
 type Json interface {
-	SuccessResponse(w http.ResponseWriter, r *http.Request, statusCode int, message interface{}, data interface{})
-	ErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message interface{})
+	// SuccessResponse writes 200 with data, or 204 without a body when data is nil.
+	SuccessResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{})
+	// CreatedResponse writes 201 with the created resource.
+	CreatedResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{})
+	// AcceptedResponse writes 202 with work queued to be done later.
+	AcceptedResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{})
+	PaginateResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{}, paginate *paginateHelper.Paginate)
+	// ErrorResponse derives the status code and error code from err.
+	ErrorResponse(w http.ResponseWriter, r *http.Request, err error)
 }
 
 type Options struct {
@@ -33,8 +43,53 @@ func (o *Options) writeJson(w http.ResponseWriter, code int, v interface{}) erro
 	return enc.Encode(v)
 }
 
+// statusCode maps an error kind to its HTTP status code.
+func statusCode(kind errorHelper.Kind) int {
+	switch kind {
+	case errorHelper.KindBadRequest:
+		return http.StatusBadRequest
+	case errorHelper.KindValidation:
+		return http.StatusUnprocessableEntity
+	case errorHelper.KindNotFound:
+		return http.StatusNotFound
+	case errorHelper.KindConflict:
+		return http.StatusConflict
+	case errorHelper.KindUnauthorized:
+		return http.StatusUnauthorized
+	case errorHelper.KindForbidden:
+		return http.StatusForbidden
+	case errorHelper.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	}
+
+	return http.StatusInternalServerError
+}
+
 // Return JSON Success
-func (o *Options) SuccessResponse(w http.ResponseWriter, r *http.Request, statusCode int, message interface{}, data interface{}) {
+func (o *Options) SuccessResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{}) {
+	o.log.CustomLog(r, "SUCCESS", data)
+
+	if data == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	o.successResponse(w, http.StatusOK, message, data)
+}
+
+// Return JSON Success of a created resource
+func (o *Options) CreatedResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{}) {
+	o.log.CustomLog(r, "SUCCESS", data)
+	o.successResponse(w, http.StatusCreated, message, data)
+}
+
+// Return JSON Success of work queued for later
+func (o *Options) AcceptedResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{}) {
+	o.log.CustomLog(r, "SUCCESS", data)
+	o.successResponse(w, http.StatusAccepted, message, data)
+}
+
+func (o *Options) successResponse(w http.ResponseWriter, statusCode int, message interface{}, data interface{}) {
 	meta := meta{
 		StatusCode: statusCode,
 		Message:    message,
@@ -45,21 +100,59 @@ func (o *Options) SuccessResponse(w http.ResponseWriter, r *http.Request, status
 		Data: data,
 	}
 
-	o.log.CustomLog(r, "SUCCESS", data)
 	o.writeJson(w, statusCode, res)
 }
 
+// Return JSON Success with cursors of the next and previous pages
+func (o *Options) PaginateResponse(w http.ResponseWriter, r *http.Request, message interface{}, data interface{}, paginate *paginateHelper.Paginate) {
+	meta := meta{
+		StatusCode: http.StatusOK,
+		Message:    message,
+	}
+
+	if paginate != nil {
+		meta.NextCursor = paginate.NextCursor
+		meta.PrevCursor = paginate.PrevCursor
+	}
+
+	res := &response{
+		Meta: meta,
+		Data: data,
+	}
+
+	o.log.CustomLog(r, "SUCCESS", data)
+	o.writeJson(w, http.StatusOK, res)
+}
+
 // Return JSON Error
-func (o *Options) ErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message interface{}) {
+func (o *Options) ErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	statusCode := statusCode(errorHelper.KindOf(err))
 	meta := meta{
 		StatusCode: statusCode,
-		Message:    message.(error).Error(),
+		Message:    err.Error(),
+		Code:       "internal_error",
+	}
+
+	var coded codedError
+	if errors.As(err, &coded) {
+		meta.Code = coded.ErrorCode()
+	}
+
+	var detailed detailedError
+	if errors.As(err, &detailed) {
+		meta.Errors = detailed.ErrorDetails()
 	}
 
 	res := &response{
 		Meta: meta,
 	}
 
-	o.log.CustomLog(r, "ERROR", message)
+	// client mistakes are expected, only server failures are errors
+	level := "ERROR"
+	if statusCode < http.StatusInternalServerError {
+		level = "WARN"
+	}
+
+	o.log.CustomLog(r, level, err)
 	o.writeJson(w, statusCode, res)
 }
@@ -8,6 +8,7 @@ import (
 	http "net/http"
 	reflect "reflect"
 
+	paginateHelper "github.com/ahsansandiah/dpo-test/helpers/paginate"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,26 +35,62 @@ func (m *MockJson) EXPECT() *MockJsonMockRecorder {
 	return m.recorder
 }
 
+// AcceptedResponse mocks base method.
+func (m *MockJson) AcceptedResponse(w http.ResponseWriter, r *http.Request, message, data interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AcceptedResponse", w, r, message, data)
+}
+
+// AcceptedResponse indicates an expected call of AcceptedResponse.
+func (mr *MockJsonMockRecorder) AcceptedResponse(w, r, message, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptedResponse", reflect.TypeOf((*MockJson)(nil).AcceptedResponse), w, r, message, data)
+}
+
+// CreatedResponse mocks base method.
+func (m *MockJson) CreatedResponse(w http.ResponseWriter, r *http.Request, message, data interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreatedResponse", w, r, message, data)
+}
+
+// CreatedResponse indicates an expected call of CreatedResponse.
+func (mr *MockJsonMockRecorder) CreatedResponse(w, r, message, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatedResponse", reflect.TypeOf((*MockJson)(nil).CreatedResponse), w, r, message, data)
+}
+
 // ErrorResponse mocks base method.
-func (m *MockJson) ErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message interface{}) {
+func (m *MockJson) ErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "ErrorResponse", w, r, statusCode, message)
+	m.ctrl.Call(m, "ErrorResponse", w, r, err)
 }
 
 // ErrorResponse indicates an expected call of ErrorResponse.
-func (mr *MockJsonMockRecorder) ErrorResponse(w, r, statusCode, message interface{}) *gomock.Call {
+func (mr *MockJsonMockRecorder) ErrorResponse(w, r, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ErrorResponse", reflect.TypeOf((*MockJson)(nil).ErrorResponse), w, r, err)
+}
+
+// PaginateResponse mocks base method.
+func (m *MockJson) PaginateResponse(w http.ResponseWriter, r *http.Request, message, data interface{}, paginate *paginateHelper.Paginate) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PaginateResponse", w, r, message, data, paginate)
+}
+
+// PaginateResponse indicates an expected call of PaginateResponse.
+func (mr *MockJsonMockRecorder) PaginateResponse(w, r, message, data, paginate interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ErrorResponse", reflect.TypeOf((*MockJson)(nil).ErrorResponse), w, r, statusCode, message)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaginateResponse", reflect.TypeOf((*MockJson)(nil).PaginateResponse), w, r, message, data, paginate)
 }
 
 // SuccessResponse mocks base method.
-func (m *MockJson) SuccessResponse(w http.ResponseWriter, r *http.Request, statusCode int, message, data interface{}) {
+func (m *MockJson) SuccessResponse(w http.ResponseWriter, r *http.Request, message, data interface{}) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SuccessResponse", w, r, statusCode, message, data)
+	m.ctrl.Call(m, "SuccessResponse", w, r, message, data)
 }
 
 // SuccessResponse indicates an expected call of SuccessResponse.
-func (mr *MockJsonMockRecorder) SuccessResponse(w, r, statusCode, message, data interface{}) *gomock.Call {
+func (mr *MockJsonMockRecorder) SuccessResponse(w, r, message, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuccessResponse", reflect.TypeOf((*MockJson)(nil).SuccessResponse), w, r, statusCode, message, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuccessResponse", reflect.TypeOf((*MockJson)(nil).SuccessResponse), w, r, message, data)
 }
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"context"
+
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields are extra key values attached to a log line.
+type Fields map[string]interface{}
+
+// WithFields returns a context whose log lines carry the given fields on top
+// of the ones already bound to ctx.
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+	for k, v := range boundFields(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, config.ContextKey("logFields"), merged)
+}
+
+func boundFields(ctx context.Context) Fields {
+	fields, _ := ctx.Value(config.ContextKey("logFields")).(Fields)
+	return fields
+}
+
+// contextFields are the fields every log line of a request carries: the
+// request id, the authenticated user, the matched route, the current trace
+// and anything bound with WithFields.
+func contextFields(ctx context.Context) Fields {
+	fields := Fields{}
+	for k, v := range boundFields(ctx) {
+		fields[k] = v
+	}
+
+	id, _ := ctx.Value(config.ContextKey("id")).(string)
+	fields["id"] = id
+	fields["user_id"] = principalAuth.UserID(ctx)
+	if route, ok := ctx.Value(config.ContextKey("route")).(string); ok && route != "" {
+		fields["route"] = route
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields["trace_id"] = spanContext.TraceID().String()
+		fields["span_id"] = spanContext.SpanID().String()
+	}
+
+	return fields
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextFields(t *testing.T) {
+	ctx := context.WithValue(context.Background(), config.ContextKey("id"), "req-1")
+	ctx = context.WithValue(ctx, config.ContextKey("route"), "/orders/{id}")
+	ctx = WithFields(ctx, Fields{"order_id": 7})
+	ctx = WithFields(ctx, Fields{"customer_id": 3})
+
+	fields := contextFields(ctx)
+
+	assert.Equal(t, "req-1", fields["id"])
+	assert.Equal(t, "/orders/{id}", fields["route"])
+	assert.Equal(t, 7, fields["order_id"])
+	assert.Equal(t, 3, fields["customer_id"])
+}
+
+func TestWithFieldsDoesNotLeakToParent(t *testing.T) {
+	parent := WithFields(context.Background(), Fields{"a": 1})
+	_ = WithFields(parent, Fields{"b": 2})
+
+	_, ok := contextFields(parent)["b"]
+	assert.False(t, ok)
+}
+
+func TestContextFieldsCarryTrace(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	fields := contextFields(ctx)
+
+	assert.Equal(t, spanContext.TraceID().String(), fields["trace_id"])
+	assert.Equal(t, spanContext.SpanID().String(), fields["span_id"])
+
+	_, ok := contextFields(context.Background())["trace_id"]
+	assert.False(t, ok)
+}
@@ -2,129 +2,135 @@ package log
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	traceHelper "github.com/ahsansandiah/dpo-test/helpers/trace"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Log interface {
+	Debug(ctx context.Context, msg string, fields Fields)
+	Info(ctx context.Context, msg string, fields Fields)
+	Warn(ctx context.Context, msg string, fields Fields)
+	Error(ctx context.Context, msg string, fields Fields)
 	ErrorLog(ctx context.Context, err error)
 	CustomLog(r *http.Request, level string, data interface{})
 	HttpLog(ctx context.Context, r *http.Request, payload []byte, response []byte)
 }
 
 type Options struct {
+	logger *log.Logger
+	redact redactor
 }
 
-func NewLog() Log {
+// NewLog writes JSON lines to stdout at LOG_LEVEL (debug, info, warn or
+// error, defaults to info), masking the keys of LOG_REDACT_KEYS on top of
+// the default sensitive ones.
+func NewLog(cfg *config.Config) Log {
 	opt := new(Options)
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetOutput(os.Stdout)
+	opt.logger = log.New()
+	opt.logger.SetFormatter(&log.JSONFormatter{})
+	opt.logger.SetOutput(os.Stdout)
+
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		level = log.InfoLevel
+	}
+	opt.logger.SetLevel(level)
+	opt.redact = newRedactor(cfg.LogRedactKeys)
 
 	return opt
 }
 
+// entry is a log entry carrying the request fields bound to ctx.
+func (o *Options) entry(ctx context.Context, fields Fields) *log.Entry {
+	all := contextFields(ctx)
+	for k, v := range fields {
+		all[k] = v
+	}
+
+	return o.logger.WithFields(log.Fields(all))
+}
+
+func (o *Options) Debug(ctx context.Context, msg string, fields Fields) {
+	o.entry(ctx, fields).Debug(msg)
+}
+
+func (o *Options) Info(ctx context.Context, msg string, fields Fields) {
+	o.entry(ctx, fields).Info(msg)
+}
+
+func (o *Options) Warn(ctx context.Context, msg string, fields Fields) {
+	o.entry(ctx, fields).Warn(msg)
+}
+
+func (o *Options) Error(ctx context.Context, msg string, fields Fields) {
+	o.entry(ctx, fields).Error(msg)
+}
+
+// ErrorLog also marks the current span as failed with err.
 func (o *Options) ErrorLog(ctx context.Context, err error) {
 	file, funcx := traceHelper.ErrorTrace(3)
-	checkID := ctx.Value(config.ContextKey("id"))
-	id := ""
-	if checkID != nil {
-		id = checkID.(string)
-	}
 
-	log.WithFields(log.Fields{
-		"id":   id,
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+
+	o.entry(ctx, Fields{
 		"file": file,
 		"func": funcx,
 	}).Error(err.Error())
 }
 
+// CustomLog logs a handled request with its response, level is SUCCESS,
+// WARN or ERROR and data is an error for the last two.
 func (o *Options) CustomLog(r *http.Request, level string, data interface{}) {
 	ctx := r.Context()
 
 	file, funcx := traceHelper.ErrorTrace(4)
-	checkID := ctx.Value(config.ContextKey("id"))
-	id := ""
-	if checkID != nil {
-		id = checkID.(string)
-	}
-
-	getParameters, _ := url.ParseQuery(r.URL.RawQuery)
-	param := make(map[string]interface{}, len(getParameters))
-	for k, v := range getParameters {
-		param[k] = v[0]
-	}
-	if len(param) == 0 {
-		param = nil
-	}
-	checkBody := ctx.Value(config.ContextKey("body"))
-	body := make(map[string]interface{})
-	if checkBody != nil {
-		getBody := checkBody.([]byte)
-		_ = json.Unmarshal(getBody, &body)
-		if len(body) == 0 {
-			body = nil
-		}
-	}
-
-	headers := make(map[string]interface{})
-	exceptionHeaders := map[string]bool{
-		"Cache-Control":   true,
-		"Postman-Token":   true,
-		"Content-Length":  true,
-		"Host":            true,
-		"User-Agent":      true,
-		"Accept-Encoding": true,
-		"Accept":          true,
-		"Connection":      true,
-	}
-	for k, v := range r.Header {
-		if !exceptionHeaders[k] {
-			headers[k] = v[0]
-		}
-	}
-	if len(headers) == 0 {
-		headers = nil
-	}
 
-	checkStartTime := ctx.Value(config.ContextKey("startTime"))
-	responseTime := time.Duration(0)
-	if checkStartTime != nil {
-		getStartTime := ctx.Value(config.ContextKey("startTime")).(time.Time)
-		responseTime = time.Duration(time.Since(getStartTime).Milliseconds())
+	var body interface{}
+	if checkBody, ok := ctx.Value(config.ContextKey("body")).([]byte); ok {
+		body = nonEmpty(o.redact.json(checkBody))
 	}
 
-	if level == "ERROR" {
-		data = data.(error).Error()
+	var response interface{}
+	if err, ok := data.(error); ok {
+		response = err.Error()
+	} else {
+		response = o.redact.data(data)
 	}
 
 	res := ResponseLog{
 		HostName:      r.Host,
 		Path:          r.URL.Path,
 		RequestMethod: r.Method,
-		Params:        param,
+		Params:        o.params(r),
 		Body:          body,
-		Headers:       headers,
+		Headers:       o.headers(r),
 		UserAgent:     r.UserAgent(),
-		ResponseTime:  responseTime,
-		Response:      data,
+		ResponseTime:  responseTime(ctx),
+		Response:      response,
 	}
 
-	logResponse := log.WithFields(log.Fields{
+	logResponse := o.entry(ctx, Fields{
 		"attributes": res,
-		"id":         id,
 		"file":       file,
 		"func":       funcx,
 	})
-	if level == "ERROR" {
-		logResponse.Error(data)
-	} else {
+	switch strings.ToUpper(level) {
+	case "ERROR":
+		logResponse.Error(response)
+	case "WARN":
+		logResponse.Warn(response)
+	default:
 		logResponse.Info("Success")
 	}
 }
@@ -132,37 +138,50 @@ func (o *Options) CustomLog(r *http.Request, level string, data interface{}) {
 func (o *Options) HttpLog(ctx context.Context, r *http.Request, payload []byte, response []byte) {
 
 	file, funcx := traceHelper.ErrorTrace(4)
-	checkID := ctx.Value(config.ContextKey("id"))
-	id := ""
-	if checkID != nil {
-		id = checkID.(string)
+
+	var body interface{}
+	if payload != nil {
+		body = nonEmpty(o.redact.json(payload))
+	}
+
+	var resCall interface{}
+	if response != nil {
+		resCall = nonEmpty(o.redact.json(response))
 	}
 
+	res := ResponseLog{
+		HostName:      r.Host,
+		Path:          r.URL.Path,
+		RequestMethod: r.Method,
+		Params:        o.params(r),
+		Body:          body,
+		Headers:       o.headers(r),
+		UserAgent:     r.UserAgent(),
+		ResponseTime:  responseTime(ctx),
+		Response:      resCall,
+	}
+
+	o.entry(ctx, Fields{
+		"attributes": res,
+		"file":       file,
+		"func":       funcx,
+	}).Info("HTTP Call Trace")
+}
+
+func (o *Options) params(r *http.Request) map[string]interface{} {
 	getParameters, _ := url.ParseQuery(r.URL.RawQuery)
 	param := make(map[string]interface{}, len(getParameters))
 	for k, v := range getParameters {
 		param[k] = v[0]
 	}
 	if len(param) == 0 {
-		param = nil
+		return nil
 	}
 
-	body := make(map[string]interface{})
-	if payload != nil {
-		_ = json.Unmarshal(payload, &body)
-		if len(body) == 0 {
-			body = nil
-		}
-	}
-
-	resCall := make(map[string]interface{})
-	if response != nil {
-		_ = json.Unmarshal(response, &resCall)
-		if len(resCall) == 0 {
-			resCall = nil
-		}
-	}
+	return o.redact.fields(param)
+}
 
+func (o *Options) headers(r *http.Request) map[string]interface{} {
 	headers := make(map[string]interface{})
 	exceptionHeaders := map[string]bool{
 		"Cache-Control":   true,
@@ -180,34 +199,26 @@ func (o *Options) HttpLog(ctx context.Context, r *http.Request, payload []byte,
 		}
 	}
 	if len(headers) == 0 {
-		headers = nil
+		return nil
 	}
 
-	checkStartTime := ctx.Value(config.ContextKey("startTime"))
-	responseTime := time.Duration(0)
-	if checkStartTime != nil {
-		getStartTime := ctx.Value(config.ContextKey("startTime")).(time.Time)
-		responseTime = time.Duration(time.Since(getStartTime).Milliseconds())
-	}
+	return o.redact.fields(headers)
+}
 
-	res := ResponseLog{
-		HostName:      r.Host,
-		Path:          r.URL.Path,
-		RequestMethod: r.Method,
-		Params:        param,
-		Body:          body,
-		Headers:       headers,
-		UserAgent:     r.UserAgent(),
-		ResponseTime:  responseTime,
-		Response:      resCall,
+func responseTime(ctx context.Context) time.Duration {
+	startTime, ok := ctx.Value(config.ContextKey("startTime")).(time.Time)
+	if !ok {
+		return 0
 	}
 
-	logResponse := log.WithFields(log.Fields{
-		"attributes": res,
-		"id":         id,
-		"file":       file,
-		"func":       funcx,
-	})
+	return time.Duration(time.Since(startTime).Milliseconds())
+}
+
+// nonEmpty drops empty JSON objects so they are logged as null.
+func nonEmpty(v interface{}) interface{} {
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 0 {
+		return nil
+	}
 
-	logResponse.Info("HTTP Call Trace")
+	return v
 }
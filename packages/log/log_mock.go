@@ -47,6 +47,30 @@ func (mr *MockLogMockRecorder) CustomLog(r, level, data interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomLog", reflect.TypeOf((*MockLog)(nil).CustomLog), r, level, data)
 }
 
+// Debug mocks base method.
+func (m *MockLog) Debug(ctx context.Context, msg string, fields Fields) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Debug", ctx, msg, fields)
+}
+
+// Debug indicates an expected call of Debug.
+func (mr *MockLogMockRecorder) Debug(ctx, msg, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debug", reflect.TypeOf((*MockLog)(nil).Debug), ctx, msg, fields)
+}
+
+// Error mocks base method.
+func (m *MockLog) Error(ctx context.Context, msg string, fields Fields) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Error", ctx, msg, fields)
+}
+
+// Error indicates an expected call of Error.
+func (mr *MockLogMockRecorder) Error(ctx, msg, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLog)(nil).Error), ctx, msg, fields)
+}
+
 // ErrorLog mocks base method.
 func (m *MockLog) ErrorLog(ctx context.Context, err error) {
 	m.ctrl.T.Helper()
@@ -70,3 +94,27 @@ func (mr *MockLogMockRecorder) HttpLog(ctx, r, payload, response interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HttpLog", reflect.TypeOf((*MockLog)(nil).HttpLog), ctx, r, payload, response)
 }
+
+// Info mocks base method.
+func (m *MockLog) Info(ctx context.Context, msg string, fields Fields) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Info", ctx, msg, fields)
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockLogMockRecorder) Info(ctx, msg, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockLog)(nil).Info), ctx, msg, fields)
+}
+
+// Warn mocks base method.
+func (m *MockLog) Warn(ctx context.Context, msg string, fields Fields) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Warn", ctx, msg, fields)
+}
+
+// Warn indicates an expected call of Warn.
+func (mr *MockLogMockRecorder) Warn(ctx, msg, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warn", reflect.TypeOf((*MockLog)(nil).Warn), ctx, msg, fields)
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// defaultRedactKeys are always masked, LOG_REDACT_KEYS adds to them.
+var defaultRedactKeys = []string{
+	"password",
+	"password_confirm",
+	"password_hash",
+	"token",
+	"access_token",
+	"refresh_token",
+	"secret",
+	"authorization",
+	"cookie",
+	"set-cookie",
+}
+
+// redactor masks the values of sensitive keys in bodies, headers and
+// responses. Keys are matched case insensitively at any depth.
+type redactor struct {
+	keys map[string]bool
+}
+
+func newRedactor(extra string) redactor {
+	r := redactor{keys: map[string]bool{}}
+	for _, key := range defaultRedactKeys {
+		r.keys[key] = true
+	}
+	for _, key := range strings.Split(extra, ",") {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys[key] = true
+		}
+	}
+
+	return r
+}
+
+func (r redactor) sensitive(key string) bool {
+	return r.keys[strings.ToLower(key)]
+}
+
+// value returns a copy of a decoded JSON value with sensitive keys masked.
+func (r redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return r.fields(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.value(item)
+		}
+		return out
+	}
+
+	return v
+}
+
+func (r redactor) fields(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if r.sensitive(k) {
+			out[k] = redacted
+			continue
+		}
+		out[k] = r.value(v)
+	}
+
+	return out
+}
+
+// data masks any value by going through its JSON form, values that do not
+// encode are dropped.
+func (r redactor) data(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	return r.json(raw)
+}
+
+// json decodes a JSON document and masks it, nil when it is not JSON.
+func (r redactor) json(raw []byte) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+
+	return r.value(decoded)
+}
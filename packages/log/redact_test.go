@@ -0,0 +1,59 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactMasksNestedKeys(t *testing.T) {
+	r := newRedactor("")
+
+	got := r.json([]byte(`{"username":"budi","password":"secret123","data":{"Token":"abc","roles":[{"name":"admin","refresh_token":"def"}]}}`))
+
+	assert.Equal(t, map[string]interface{}{
+		"username": "budi",
+		"password": redacted,
+		"data": map[string]interface{}{
+			"Token": redacted,
+			"roles": []interface{}{
+				map[string]interface{}{"name": "admin", "refresh_token": redacted},
+			},
+		},
+	}, got)
+}
+
+func TestRedactHeadersCaseInsensitive(t *testing.T) {
+	r := newRedactor("")
+
+	got := r.fields(map[string]interface{}{"Authorization": "Bearer abc", "Content-Type": "application/json"})
+
+	assert.Equal(t, redacted, got["Authorization"])
+	assert.Equal(t, "application/json", got["Content-Type"])
+}
+
+func TestRedactExtraKeys(t *testing.T) {
+	r := newRedactor(" phone , Email")
+
+	got := r.data(struct {
+		Phone    string `json:"phone"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Name     string `json:"name"`
+	}{"0812", "a@b.c", "x", "Budi"})
+
+	assert.Equal(t, map[string]interface{}{
+		"phone":    redacted,
+		"email":    redacted,
+		"password": redacted,
+		"name":     "Budi",
+	}, got)
+}
+
+func TestRedactNonJSON(t *testing.T) {
+	r := newRedactor("")
+
+	assert.Nil(t, r.json([]byte("not json")))
+	assert.Nil(t, r.data(nil))
+	assert.Equal(t, "plain", r.data("plain"))
+}
@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+const defaultDrainTimeout = 15 * time.Second
+
+// Hook is a subsystem started and stopped with the application, either
+// function may be nil. Start must not block: long running work belongs in a
+// goroutine reporting a failure through Lifecycle.Fail. Stop returns once
+// the subsystem has drained or ctx is done, whichever comes first.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+type Lifecycle interface {
+	Append(hook Hook)
+	Fail(err error)
+	Run(ctx context.Context) error
+}
+
+type lifecycle struct {
+	log          logger.Log
+	drainTimeout time.Duration
+	failed       chan error
+
+	mu    sync.Mutex
+	hooks []Hook
+}
+
+// NewLifecycle stops the hooks within SHUTDOWN_DRAIN_TIMEOUT_SECONDS,
+// defaults to 15.
+func NewLifecycle(cfg *config.Config, lg logger.Log) Lifecycle {
+	lc := &lifecycle{
+		log:          lg,
+		drainTimeout: time.Duration(cfg.ShutdownDrainTimeout) * time.Second,
+		failed:       make(chan error, 1),
+	}
+
+	if lc.drainTimeout <= 0 {
+		lc.drainTimeout = defaultDrainTimeout
+	}
+
+	return lc
+}
+
+// Append registers a hook. Hooks start in the order they were appended and
+// stop in the reverse one, so a hook can rely on those appended before it.
+func (l *lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+// Fail shuts the application down with err, only the first failure is kept.
+func (l *lifecycle) Fail(err error) {
+	select {
+	case l.failed <- err:
+	default:
+	}
+}
+
+// Run starts the hooks and blocks until SIGINT or SIGTERM, ctx is done or a
+// hook fails, then stops the hooks that were started. The failure, or else
+// the first error of a stop, is returned.
+func (l *lifecycle) Run(ctx context.Context) error {
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	l.mu.Lock()
+	hooks := append([]Hook(nil), l.hooks...)
+	l.mu.Unlock()
+
+	started, err := l.start(ctx, hooks)
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			l.log.Info(ctx, "shutting down", nil)
+		case err = <-l.failed:
+			l.log.Error(ctx, "shutting down after a failure", logger.Fields{"error": err.Error()})
+		}
+	}
+
+	if stopErr := l.stop(started); err == nil {
+		err = stopErr
+	}
+
+	return err
+}
+
+// start runs the Start of every hook in order and returns those that started.
+func (l *lifecycle) start(ctx context.Context, hooks []Hook) ([]Hook, error) {
+	for i, hook := range hooks {
+		if hook.Start == nil {
+			continue
+		}
+
+		if err := hook.Start(ctx); err != nil {
+			l.log.Error(ctx, "hook failed to start", logger.Fields{"hook": hook.Name, "error": err.Error()})
+			return hooks[:i], err
+		}
+	}
+
+	return hooks, nil
+}
+
+// stop runs the Stop of the hooks in reverse order, all of them sharing the
+// drain timeout. A hook is still stopped once the timeout passed so it can
+// release what it holds.
+func (l *lifecycle) stop(hooks []Hook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.drainTimeout)
+	defer cancel()
+
+	var first error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.Stop == nil {
+			continue
+		}
+
+		if err := hook.Stop(ctx); err != nil {
+			l.log.Error(ctx, "hook failed to stop", logger.Fields{"hook": hook.Name, "error": err.Error()})
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	l.log.Info(ctx, "stopped", nil)
+
+	return first
+}
+
+// WorkerHook runs work in the background until the application stops, Stop
+// cancels its context and waits for it to return.
+func WorkerHook(name string, work func(ctx context.Context)) Hook {
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+
+			go func() {
+				defer close(done)
+				work(ctx)
+			}()
+
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
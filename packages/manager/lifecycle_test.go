@@ -0,0 +1,104 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLifecycle(drainTimeout int) Lifecycle {
+	cfg := &config.Config{LogLevel: "panic", ShutdownDrainTimeout: drainTimeout}
+	return NewLifecycle(cfg, logger.NewLog(cfg))
+}
+
+func recordingHook(name string, calls *[]string, startErr error) Hook {
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		Stop: func(context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycleStopsInReverseOrder(t *testing.T) {
+	var calls []string
+	lc := newTestLifecycle(0)
+	lc.Append(recordingHook("database", &calls, nil))
+	lc.Append(recordingHook("server", &calls, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, lc.Run(ctx))
+	assert.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, calls)
+}
+
+func TestLifecycleStopsOnlyStartedHooks(t *testing.T) {
+	var calls []string
+	lc := newTestLifecycle(0)
+	lc.Append(recordingHook("database", &calls, nil))
+	lc.Append(recordingHook("server", &calls, errors.New("address already in use")))
+	lc.Append(recordingHook("health", &calls, nil))
+
+	err := lc.Run(context.Background())
+
+	assert.EqualError(t, err, "address already in use")
+	assert.Equal(t, []string{"start database", "start server", "stop database"}, calls)
+}
+
+func TestLifecycleShutsDownOnFailure(t *testing.T) {
+	lc := newTestLifecycle(0)
+	lc.Append(Hook{Name: "server", Start: func(context.Context) error {
+		go lc.Fail(errors.New("listener closed"))
+		return nil
+	}})
+
+	assert.EqualError(t, lc.Run(context.Background()), "listener closed")
+}
+
+func TestLifecycleBoundsTheDrain(t *testing.T) {
+	var stopped bool
+	lc := newTestLifecycle(1)
+	lc.Append(Hook{Name: "database", Stop: func(ctx context.Context) error {
+		// still called, with the expired context, after a hook drained too long
+		stopped = ctx.Err() != nil
+		return nil
+	}})
+	lc.Append(Hook{Name: "server", Stop: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	begin := time.Now()
+	err := lc.Run(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, stopped)
+	assert.Less(t, time.Since(begin), 2*time.Second)
+}
+
+func TestWorkerHookWaitsForWork(t *testing.T) {
+	var finished bool
+	hook := WorkerHook("relay", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	})
+
+	assert.NoError(t, hook.Start(context.Background()))
+	assert.NoError(t, hook.Stop(context.Background()))
+	assert.True(t, finished)
+}
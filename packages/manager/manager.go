@@ -3,49 +3,87 @@ package manager
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	denylistAuth "github.com/ahsansandiah/dpo-test/packages/auth/denylist"
 	jwtAuth "github.com/ahsansandiah/dpo-test/packages/auth/jwt"
 	middlewareAuth "github.com/ahsansandiah/dpo-test/packages/auth/middleware"
 	httpClient "github.com/ahsansandiah/dpo-test/packages/client"
 	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/health"
 	"github.com/ahsansandiah/dpo-test/packages/json"
 	logger "github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/ahsansandiah/dpo-test/packages/metrics"
+	"github.com/ahsansandiah/dpo-test/packages/outbox"
+	"github.com/ahsansandiah/dpo-test/packages/ratelimit"
 	"github.com/ahsansandiah/dpo-test/packages/server"
+	idempotencyDatabase "github.com/ahsansandiah/dpo-test/packages/storage/idempotency"
 	database "github.com/ahsansandiah/dpo-test/packages/storage/mysql"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+	"github.com/ahsansandiah/dpo-test/packages/telemetry"
 )
 
+// idempotencyPurgeInterval is how often expired idempotency keys are deleted.
+const idempotencyPurgeInterval = time.Hour
+
 type Manager interface {
 	GetConfig() *config.Config
 	GetServer() *server.Server
 	GetDB() *sql.DB
+	GetTransaction() transactionDatabase.Transaction
 	GetLog() logger.Log
 	GetJson() json.Json
 	GetHttp() httpClient.Http
 	GetMiddleware() middlewareAuth.Middleware
 	GetJwt() jwtAuth.Jwt
+	GetDenylist() denylistAuth.Denylist
+	GetOutbox() outbox.Outbox
+	GetTelemetry() telemetry.Telemetry
+	GetMetrics() metrics.Metrics
+	GetHealth() health.Health
+	GetLifecycle() Lifecycle
 }
 
 type manager struct {
 	config         *config.Config
 	server         *server.Server
 	db             *sql.DB
+	transaction    transactionDatabase.Transaction
 	logger         logger.Log
 	json           json.Json
 	httpClient     httpClient.Http
 	jwtAuth        jwtAuth.Jwt
 	middlewareAuth middlewareAuth.Middleware
+	denylistAuth   denylistAuth.Denylist
+	outbox         outbox.Outbox
+	telemetry      telemetry.Telemetry
+	metrics        metrics.Metrics
+	health         health.Health
+	lifecycle      Lifecycle
 }
 
 func NewInit() (Manager, error) {
-	lg := logger.NewLog()
 	ctx := context.Background()
 
 	cfg, err := config.NewConfig()
+	if err != nil {
+		logger.NewLog(&config.Config{}).ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	lg := logger.NewLog(cfg)
+
+	// tracing comes before anything that may open a span
+	tel, err := telemetry.NewTelemetry(cfg)
 	if err != nil {
 		lg.ErrorLog(ctx, err)
 		return nil, err
 	}
 
+	// stopped last so the spans of the shutdown are flushed
+	lc := NewLifecycle(cfg, lg)
+	lc.Append(Hook{Name: "telemetry", Stop: tel.Shutdown})
+
 	srv := server.NewServer(cfg)
 	database, err := database.NewMySQL(cfg).Connect()
 	if err != nil {
@@ -53,24 +91,65 @@ func NewInit() (Manager, error) {
 		return nil, err
 	}
 
+	lc.Append(Hook{Name: "database", Stop: func(context.Context) error {
+		return database.Close()
+	}})
+
+	transaction := transactionDatabase.NewTransaction(database, lg)
+
+	mt, err := metrics.NewMetrics(cfg, database)
+	if err != nil {
+		lg.ErrorLog(ctx, err)
+		return nil, err
+	}
+
 	jwt := jwtAuth.NewJwt(cfg)
 
-	clHttp := httpClient.NewHttp(cfg, lg)
+	clHttp := httpClient.NewHttp(cfg, lg, mt)
 	clHttp.Connect()
 
 	json := json.NewJson(lg)
 
-	middleware := middlewareAuth.NewMiddleware(cfg, lg, json)
+	denylist := denylistAuth.NewDenylist(database, lg)
+
+	idempotency := idempotencyDatabase.NewIdempotency(database, lg)
+	lc.Append(WorkerHook("idempotency purge", idempotencyDatabase.PurgeEvery(idempotency, idempotencyPurgeInterval)))
+
+	limiter, err := ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore())
+	if err != nil {
+		lg.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	middleware := middlewareAuth.NewMiddleware(cfg, lg, json, denylist, idempotency, limiter)
+
+	ob := outbox.NewOutbox(transaction, lg)
+
+	migrationsDir := cfg.MigrationsDir
+	if migrationsDir == "" {
+		migrationsDir = "migrations"
+	}
+
+	hc := health.NewHealth(cfg, lg, mt.Allowed)
+	hc.Register("database", health.DatabaseCheck(database))
+	hc.Register("migrations", health.MigrationCheck(database, migrationsDir))
 
 	return &manager{
 		config:         cfg,
 		server:         srv,
 		db:             database,
+		transaction:    transaction,
 		logger:         lg,
 		httpClient:     clHttp,
 		json:           json,
 		jwtAuth:        jwt,
 		middlewareAuth: middleware,
+		denylistAuth:   denylist,
+		outbox:         ob,
+		telemetry:      tel,
+		metrics:        mt,
+		health:         hc,
+		lifecycle:      lc,
 	}, nil
 }
 
@@ -86,6 +165,10 @@ func (sm *manager) GetDB() *sql.DB {
 	return sm.db
 }
 
+func (sm *manager) GetTransaction() transactionDatabase.Transaction {
+	return sm.transaction
+}
+
 func (sm *manager) GetLog() logger.Log {
 	return sm.logger
 }
@@ -104,3 +187,27 @@ func (sm *manager) GetJwt() jwtAuth.Jwt {
 func (sm *manager) GetMiddleware() middlewareAuth.Middleware {
 	return sm.middlewareAuth
 }
+
+func (sm *manager) GetDenylist() denylistAuth.Denylist {
+	return sm.denylistAuth
+}
+
+func (sm *manager) GetOutbox() outbox.Outbox {
+	return sm.outbox
+}
+
+func (sm *manager) GetTelemetry() telemetry.Telemetry {
+	return sm.telemetry
+}
+
+func (sm *manager) GetMetrics() metrics.Metrics {
+	return sm.metrics
+}
+
+func (sm *manager) GetHealth() health.Health {
+	return sm.health
+}
+
+func (sm *manager) GetLifecycle() Lifecycle {
+	return sm.lifecycle
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// access decides who may scrape the metrics. A configured token and a
+// configured CIDR list must both match, with neither only loopback
+// addresses are let in.
+type access struct {
+	token    string
+	networks []*net.IPNet
+}
+
+func newAccess(token, cidrs string) (access, error) {
+	a := access{token: token}
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return access{}, fmt.Errorf("METRICS_ALLOWED_CIDRS: %w", err)
+		}
+		a.networks = append(a.networks, network)
+	}
+
+	return a, nil
+}
+
+func (a access) allowed(r *http.Request) bool {
+	ip := remoteIP(r)
+
+	if a.token == "" && len(a.networks) == 0 {
+		return ip != nil && ip.IsLoopback()
+	}
+
+	if a.token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			return false
+		}
+	}
+
+	if len(a.networks) > 0 {
+		if ip == nil {
+			return false
+		}
+		for _, network := range a.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// remoteIP is the address of the connection, forwarded headers are not
+// trusted.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
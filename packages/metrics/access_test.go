@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func request(remoteAddr, authorization string) *http.Request {
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	r.RemoteAddr = remoteAddr
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	return r
+}
+
+func TestAccessDefaultsToLoopback(t *testing.T) {
+	a, err := newAccess("", "")
+	assert.NoError(t, err)
+
+	assert.True(t, a.allowed(request("127.0.0.1:5000", "")))
+	assert.True(t, a.allowed(request("[::1]:5000", "")))
+	assert.False(t, a.allowed(request("10.0.0.5:5000", "")))
+}
+
+func TestAccessToken(t *testing.T) {
+	a, err := newAccess("s3cret", "")
+	assert.NoError(t, err)
+
+	assert.True(t, a.allowed(request("10.0.0.5:5000", "Bearer s3cret")))
+	assert.False(t, a.allowed(request("10.0.0.5:5000", "Bearer wrong")))
+	assert.False(t, a.allowed(request("127.0.0.1:5000", "")))
+}
+
+func TestAccessCIDRs(t *testing.T) {
+	a, err := newAccess("", "10.0.0.0/8, 192.168.1.0/24")
+	assert.NoError(t, err)
+
+	assert.True(t, a.allowed(request("10.1.2.3:5000", "")))
+	assert.True(t, a.allowed(request("192.168.1.9:5000", "")))
+	assert.False(t, a.allowed(request("192.168.2.9:5000", "")))
+}
+
+func TestAccessTokenAndCIDRsMustBothMatch(t *testing.T) {
+	a, err := newAccess("s3cret", "10.0.0.0/8")
+	assert.NoError(t, err)
+
+	assert.True(t, a.allowed(request("10.1.2.3:5000", "Bearer s3cret")))
+	assert.False(t, a.allowed(request("10.1.2.3:5000", "")))
+	assert.False(t, a.allowed(request("172.16.0.1:5000", "Bearer s3cret")))
+}
+
+func TestAccessRejectsInvalidCIDR(t *testing.T) {
+	_, err := newAccess("", "10.0.0.0/99")
+	assert.Error(t, err)
+}
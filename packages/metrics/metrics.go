@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dpo"
+
+type Metrics interface {
+	Handler() http.Handler
+	Allowed(r *http.Request) bool
+	Middleware(next http.Handler) http.Handler
+	ObserveHttpCall(host, method, status string, duration time.Duration)
+	OrderCreated(totalAmount float64)
+	OrderStatusChanged(from, to string)
+}
+
+type Options struct {
+	registry *prometheus.Registry
+	access   access
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	httpCalls           *prometheus.CounterVec
+	httpCallDuration    *prometheus.HistogramVec
+	ordersCreated       prometheus.Counter
+	orderRevenue        prometheus.Counter
+	orderTransitions    *prometheus.CounterVec
+}
+
+// NewMetrics registers the collectors on a registry of its own, the pool
+// statistics of db are included when db is not nil.
+func NewMetrics(cfg *config.Config, db *sql.DB) (Metrics, error) {
+	opt := new(Options)
+
+	access, err := newAccess(cfg.MetricsToken, cfg.MetricsAllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	opt.access = access
+
+	opt.httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route template and status.",
+	}, []string{"method", "route", "status"})
+	opt.httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests served, by route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	opt.httpCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_client_requests_total",
+		Help:      "Outbound HTTP attempts by host and status, error when no response came.",
+	}, []string{"host", "method", "status"})
+	opt.httpCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_client_request_duration_seconds",
+		Help:      "Latency of outbound HTTP attempts by host.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"host", "method"})
+	opt.ordersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders created.",
+	})
+	opt.orderRevenue = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_revenue_total",
+		Help:      "Total amount of the orders created.",
+	})
+	opt.orderTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_status_transitions_total",
+		Help:      "Order status changes by previous and new status.",
+	}, []string{"from", "to"})
+
+	opt.registry = prometheus.NewRegistry()
+	opt.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		opt.httpRequests,
+		opt.httpRequestDuration,
+		opt.httpCalls,
+		opt.httpCallDuration,
+		opt.ordersCreated,
+		opt.orderRevenue,
+		opt.orderTransitions,
+	)
+	if db != nil {
+		opt.registry.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+	}
+
+	return opt, nil
+}
+
+// Handler serves the metrics to the scrapers METRICS_TOKEN and
+// METRICS_ALLOWED_CIDRS let in.
+func (o *Options) Handler() http.Handler {
+	metrics := promhttp.HandlerFor(o.registry, promhttp.HandlerOpts{})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !o.Allowed(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		metrics.ServeHTTP(w, r)
+	})
+}
+
+// Allowed reports whether r passes METRICS_TOKEN and METRICS_ALLOWED_CIDRS,
+// the rules the metrics are served by.
+func (o *Options) Allowed(r *http.Request) bool {
+	return o.access.allowed(r)
+}
+
+// Middleware counts and times requests by the template of their route, it
+// must run after routing, as a mux.Router middleware does.
+func (o *Options) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		m := httpsnoop.CaptureMetrics(next, w, r)
+
+		status := strconv.Itoa(m.Code)
+		o.httpRequests.WithLabelValues(r.Method, route, status).Inc()
+		o.httpRequestDuration.WithLabelValues(r.Method, route, status).Observe(m.Duration.Seconds())
+	})
+}
+
+func (o *Options) ObserveHttpCall(host, method, status string, duration time.Duration) {
+	o.httpCalls.WithLabelValues(host, method, status).Inc()
+	o.httpCallDuration.WithLabelValues(host, method).Observe(duration.Seconds())
+}
+
+func (o *Options) OrderCreated(totalAmount float64) {
+	o.ordersCreated.Inc()
+	o.orderRevenue.Add(totalAmount)
+}
+
+func (o *Options) OrderStatusChanged(from, to string) {
+	o.orderTransitions.WithLabelValues(from, to).Inc()
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	mt, err := NewMetrics(&config.Config{}, nil)
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(mt.Middleware)
+	router.HandleFunc("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.Handle("/metrics", mt.Handler())
+
+	for _, id := range []string{"1", "2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/"+id, nil))
+	}
+	mt.OrderCreated(150.5)
+	mt.OrderStatusChanged("Pending", "Confirmed")
+
+	scrape := httptest.NewRequest("GET", "/metrics", nil)
+	scrape.RemoteAddr = "127.0.0.1:5000"
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, scrape)
+	body := res.Body.String()
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.True(t, strings.Contains(body, `dpo_http_requests_total{method="GET",route="/orders/{id}",status="404"} 2`), body)
+	assert.True(t, strings.Contains(body, "dpo_orders_created_total 1"))
+	assert.True(t, strings.Contains(body, "dpo_order_revenue_total 150.5"))
+	assert.True(t, strings.Contains(body, `dpo_order_status_transitions_total{from="Pending",to="Confirmed"} 1`))
+}
+
+func TestHandlerForbidsOutsiders(t *testing.T) {
+	mt, err := NewMetrics(&config.Config{}, nil)
+	assert.NoError(t, err)
+
+	scrape := httptest.NewRequest("GET", "/metrics", nil)
+	scrape.RemoteAddr = "10.0.0.5:5000"
+	res := httptest.NewRecorder()
+	mt.Handler().ServeHTTP(res, scrape)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+}
@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	transactionDatabase "github.com/ahsansandiah/dpo-test/packages/storage/transaction"
+)
+
+// maxErrorLength keeps last_error readable, the full error is logged.
+const maxErrorLength = 1000
+
+// Event is a domain event waiting in the outbox to be published.
+type Event struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+	RequestID     string          `json:"request_id,omitempty"`
+	Attempts      int             `json:"-"`
+	CreatedAt     time.Time       `json:"occurred_at"`
+}
+
+type Outbox interface {
+	// Add stores an event. Inside a transaction it is stored together with
+	// the change it describes, so it is published only if the change commits.
+	Add(ctx context.Context, aggregateType string, aggregateID int64, eventType string, payload interface{}) error
+	// Claim returns up to limit events due for publishing and hides them from
+	// other relays for the lease, an event is claimed again when the relay
+	// publishing it dies.
+	Claim(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]Event, error)
+	MarkPublished(ctx context.Context, ID int64) error
+	// MarkFailed counts a failed attempt and schedules the next one.
+	MarkFailed(ctx context.Context, ID int64, nextAttemptAt time.Time, cause error) error
+}
+
+type Options struct {
+	trx transactionDatabase.Transaction
+	log log.Log
+}
+
+func NewOutbox(trx transactionDatabase.Transaction, lg log.Log) Outbox {
+	opt := new(Options)
+	opt.trx = trx
+	opt.log = lg
+
+	return opt
+}
+
+func (o *Options) Add(ctx context.Context, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	// the request id is set by the InitLog middleware
+	var requestID interface{}
+	if id, ok := ctx.Value(config.ContextKey("id")).(string); ok && id != "" {
+		requestID = id
+	}
+
+	query := "INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, request_id) VALUES (?, ?, ?, ?, ?)"
+	_, err = o.trx.Executor(ctx).ExecContext(ctx, query, aggregateType, aggregateID, eventType, string(data), requestID)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) Claim(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]Event, error) {
+	events := []Event{}
+	err := o.trx.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		query := `SELECT id, aggregate_type, aggregate_id, event_type, payload, COALESCE(request_id, ''), attempts, created_at
+                  FROM outbox
+                  WHERE published_at IS NULL AND next_attempt_at <= ? AND attempts < ?
+                  ORDER BY id
+                  LIMIT ?
+                  FOR UPDATE SKIP LOCKED`
+		rows, err := o.trx.Executor(ctx).QueryContext(ctx, query, now, maxAttempts, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var event Event
+			var payload []byte
+			if err := rows.Scan(&event.ID, &event.AggregateType, &event.AggregateID, &event.Type, &payload, &event.RequestID, &event.Attempts, &event.CreatedAt); err != nil {
+				return err
+			}
+			event.Payload = payload
+			events = append(events, event)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, 0, len(events)+1)
+		ids = append(ids, now.Add(lease))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(events)), ", ")
+		_, err = o.trx.Executor(ctx).ExecContext(ctx, "UPDATE outbox SET next_attempt_at = ? WHERE id IN ("+placeholders+")", ids...)
+		return err
+	})
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (o *Options) MarkPublished(ctx context.Context, ID int64) error {
+	_, err := o.trx.Executor(ctx).ExecContext(ctx, "UPDATE outbox SET published_at = ?, attempts = attempts + 1, last_error = NULL WHERE id = ?", time.Now(), ID)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) MarkFailed(ctx context.Context, ID int64, nextAttemptAt time.Time, cause error) error {
+	message := cause.Error()
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+
+	_, err := o.trx.Executor(ctx).ExecContext(ctx, "UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?", message, nextAttemptAt, ID)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMaxAttempts  = 10
+
+	// lease is how long a claimed event is hidden from other relays, it must
+	// outlast publishing a batch.
+	lease = time.Minute
+
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 5 * time.Minute
+)
+
+// Relay publishes the events of the outbox to the sinks. Events are
+// delivered at least once: an event is only marked published after every
+// sink accepted it, failed events are retried with an exponential backoff
+// until they run out of attempts and stay in the outbox for inspection.
+type Relay struct {
+	outbox       Outbox
+	sinks        []Sink
+	log          log.Log
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+}
+
+func NewRelay(cfg *config.Config, ob Outbox, lg log.Log, sinks ...Sink) *Relay {
+	relay := &Relay{
+		outbox:       ob,
+		sinks:        sinks,
+		log:          lg,
+		pollInterval: time.Duration(cfg.OutboxPollIntervalMs) * time.Millisecond,
+		batchSize:    cfg.OutboxBatchSize,
+		maxAttempts:  cfg.OutboxMaxAttempts,
+	}
+
+	if relay.pollInterval <= 0 {
+		relay.pollInterval = defaultPollInterval
+	}
+	if relay.batchSize <= 0 {
+		relay.batchSize = defaultBatchSize
+	}
+	if relay.maxAttempts <= 0 {
+		relay.maxAttempts = defaultMaxAttempts
+	}
+
+	return relay
+}
+
+// Run publishes events until ctx is cancelled. A full batch is followed by
+// the next one right away, otherwise the relay waits for the poll interval.
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		published, err := r.RelayOnce(ctx)
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due events and returns how many were
+// claimed.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.outbox.Claim(ctx, r.batchSize, r.maxAttempts, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := r.publish(ctx, event); err != nil {
+			r.log.Warn(ctx, "publishing event failed, retrying later", log.Fields{
+				"id":         event.RequestID,
+				"event_id":   event.ID,
+				"event_type": event.Type,
+				"attempts":   event.Attempts + 1,
+				"error":      err.Error(),
+			})
+
+			nextAttemptAt := time.Now().Add(retryDelay(event.Attempts + 1))
+			if err := r.outbox.MarkFailed(ctx, event.ID, nextAttemptAt, err); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		if err := r.outbox.MarkPublished(ctx, event.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *Relay) publish(ctx context.Context, event Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// retryDelay doubles the delay with every failed attempt, from
+// retryBaseDelay up to retryMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryOutbox is an Outbox keeping its events in a slice.
+type memoryOutbox struct {
+	events    []Event
+	published map[int64]bool
+	failures  map[int64]time.Time
+}
+
+func newMemoryOutbox(events ...Event) *memoryOutbox {
+	return &memoryOutbox{events: events, published: map[int64]bool{}, failures: map[int64]time.Time{}}
+}
+
+func (o *memoryOutbox) Add(ctx context.Context, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	o.events = append(o.events, Event{ID: int64(len(o.events) + 1), AggregateType: aggregateType, AggregateID: aggregateID, Type: eventType})
+	return nil
+}
+
+func (o *memoryOutbox) Claim(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]Event, error) {
+	claimed := []Event{}
+	for _, event := range o.events {
+		if !o.published[event.ID] && event.Attempts < maxAttempts && len(claimed) < limit {
+			claimed = append(claimed, event)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, ID int64) error {
+	o.published[ID] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(ctx context.Context, ID int64, nextAttemptAt time.Time, cause error) error {
+	for i := range o.events {
+		if o.events[i].ID == ID {
+			o.events[i].Attempts++
+		}
+	}
+	o.failures[ID] = nextAttemptAt
+
+	return nil
+}
+
+type failingSink struct{}
+
+func (s failingSink) Name() string { return "failing" }
+
+func (s failingSink) Publish(ctx context.Context, event Event) error {
+	return errors.New("unavailable")
+}
+
+type nopLog struct{}
+
+func (nopLog) Debug(ctx context.Context, msg string, fields log.Fields)               {}
+func (nopLog) Info(ctx context.Context, msg string, fields log.Fields)                {}
+func (nopLog) Warn(ctx context.Context, msg string, fields log.Fields)                {}
+func (nopLog) Error(ctx context.Context, msg string, fields log.Fields)               {}
+func (nopLog) ErrorLog(ctx context.Context, err error)                                {}
+func (nopLog) CustomLog(r *http.Request, level string, data interface{})              {}
+func (nopLog) HttpLog(ctx context.Context, r *http.Request, payload, response []byte) {}
+
+func TestRelayPublishesToEverySink(t *testing.T) {
+	ob := newMemoryOutbox(Event{ID: 1, Type: "OrderCreated"}, Event{ID: 2, Type: "OrderUpdated"})
+	first, second := NewMemorySink(), NewMemorySink()
+	relay := NewRelay(&config.Config{}, ob, nopLog{}, first, second)
+
+	claimed, err := relay.RelayOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Len(t, first.Events(), 2)
+	assert.Len(t, second.Events(), 2)
+	assert.True(t, ob.published[1])
+	assert.True(t, ob.published[2])
+}
+
+func TestRelayRetriesFailedEvents(t *testing.T) {
+	ob := newMemoryOutbox(Event{ID: 1, Type: "OrderCreated"})
+	sink := NewMemorySink()
+	relay := NewRelay(&config.Config{OutboxMaxAttempts: 2}, ob, nopLog{}, sink, failingSink{})
+
+	for i := 0; i < 3; i++ {
+		_, err := relay.RelayOnce(context.Background())
+		assert.NoError(t, err)
+	}
+
+	// delivered again on every attempt until the attempts ran out
+	assert.Len(t, sink.Events(), 2)
+	assert.False(t, ob.published[1])
+	assert.Equal(t, 2, ob.events[0].Attempts)
+	assert.True(t, ob.failures[1].After(time.Now()))
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, retryDelay(1))
+	assert.Equal(t, 4*time.Second, retryDelay(3))
+	assert.Equal(t, retryMaxDelay, retryDelay(20))
+}
@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	httpClient "github.com/ahsansandiah/dpo-test/packages/client"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+// Sink delivers events to a downstream system. An event is published once
+// every sink accepted it, a failing sink makes the relay retry the event on
+// all sinks, so sinks must tolerate duplicates.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event Event) error
+}
+
+// WebhookSink posts every event as JSON to a URL. The event id is sent in the
+// X-Event-Id header so receivers can drop duplicates.
+type WebhookSink struct {
+	http httpClient.Http
+	url  string
+}
+
+func NewWebhookSink(http httpClient.Http, url string) *WebhookSink {
+	return &WebhookSink{http: http, url: url}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	header := map[string]string{
+		"Content-Type": "application/json",
+		"X-Event-Id":   strconv.FormatInt(event.ID, 10),
+		"X-Event-Type": event.Type,
+	}
+
+	_, err = s.http.CallURL(ctx, "POST", s.url, header, body)
+	return err
+}
+
+// LogSink writes every event to the application log.
+type LogSink struct {
+	log log.Log
+}
+
+func NewLogSink(lg log.Log) *LogSink {
+	return &LogSink{log: lg}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(ctx context.Context, event Event) error {
+	s.log.Info(ctx, "domain event", log.Fields{
+		"id":             event.RequestID,
+		"event_id":       event.ID,
+		"event_type":     event.Type,
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+		"payload":        string(event.Payload),
+	})
+
+	return nil
+}
+
+// MemorySink keeps published events in memory, it is meant for tests.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Name() string {
+	return "memory"
+}
+
+func (s *MemorySink) Publish(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+// Events returns the events published so far.
+func (s *MemorySink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]Event, len(s.events))
+	copy(events, s.events)
+
+	return events
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit lets Requests through per Period, refilled continuously, so a client
+// may burst up to Requests after staying quiet for a Period. The zero Limit
+// lets everything through.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l Limit) disabled() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// rate is the number of tokens refilled per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// parseLimit reads a limit written as <requests>/<period>, e.g. 10/1m, or
+// off.
+func parseLimit(spec string) (Limit, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "off" {
+		return Limit{}, nil
+	}
+
+	parts := strings.SplitN(spec, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("limit %q must be <requests>/<period> or off", spec)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must allow a positive number of requests", spec)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive period such as 1s or 1m", spec)
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// parseLimits reads comma separated <route>=<limit> pairs, e.g.
+// auth=10/1m,api=100/1m, into limits.
+func parseLimits(spec string, limits map[string]Limit) error {
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("route limit %q must be <route>=<limit>", pair)
+		}
+
+		limit, err := parseLimit(parts[1])
+		if err != nil {
+			return err
+		}
+		limits[strings.TrimSpace(parts[0])] = limit
+	}
+
+	return nil
+}
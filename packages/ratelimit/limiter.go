@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+)
+
+var (
+	defaultLimit = Limit{Requests: 100, Period: time.Minute}
+
+	// defaultRouteLimits protect the unauthenticated routes from guessing
+	// passwords
+	defaultRouteLimits = map[string]Limit{
+		"auth": {Requests: 10, Period: time.Minute},
+	}
+)
+
+type Limiter interface {
+	// Allow takes a token for the client of r from the bucket of route.
+	Allow(r *http.Request, route string) (Result, error)
+}
+
+type Options struct {
+	store          Store
+	limits         map[string]Limit
+	fallback       Limit
+	trustedProxies []*net.IPNet
+}
+
+// NewLimiter limits the routes named in RATE_LIMIT_ROUTES and the others to
+// RATE_LIMIT_DEFAULT. Clients are told apart by the user of their access
+// token, or else by their IP, read from X-Forwarded-For only when the
+// connection comes from RATE_LIMIT_TRUSTED_PROXIES.
+func NewLimiter(cfg *config.Config, store Store) (Limiter, error) {
+	opt := new(Options)
+	opt.store = store
+	opt.fallback = defaultLimit
+	opt.limits = map[string]Limit{}
+	for route, limit := range defaultRouteLimits {
+		opt.limits[route] = limit
+	}
+
+	if cfg.RateLimitDefault != "" {
+		limit, err := parseLimit(cfg.RateLimitDefault)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_DEFAULT: %w", err)
+		}
+		opt.fallback = limit
+	}
+
+	if err := parseLimits(cfg.RateLimitRoutes, opt.limits); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+	}
+
+	for _, cidr := range strings.Split(cfg.RateLimitTrustedProxies, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_TRUSTED_PROXIES: %w", err)
+		}
+		opt.trustedProxies = append(opt.trustedProxies, network)
+	}
+
+	return opt, nil
+}
+
+func (o *Options) Allow(r *http.Request, route string) (Result, error) {
+	limit, ok := o.limits[route]
+	if !ok {
+		limit = o.fallback
+	}
+
+	if limit.disabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	return o.store.Take(r.Context(), route+":"+o.client(r), limit)
+}
+
+// client identifies the client of r by its user when it carries an access
+// token checked by CheckToken, and by its IP otherwise.
+func (o *Options) client(r *http.Request) string {
+	if principal, ok := principalAuth.FromContext(r.Context()); ok {
+		return "user:" + strconv.FormatInt(principal.UserID, 10)
+	}
+
+	return "ip:" + o.clientIP(r)
+}
+
+// clientIP is the address of the connection, or when it is a trusted proxy
+// the last address of X-Forwarded-For not added by a trusted proxy.
+func (o *Options) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !o.trusted(host) {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+
+		host = hop
+		if !o.trusted(hop) {
+			break
+		}
+	}
+
+	return host
+}
+
+func (o *Options) trusted(host string) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range o.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
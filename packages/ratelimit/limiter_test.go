@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	principalAuth "github.com/ahsansandiah/dpo-test/packages/auth/principal"
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLimiterReadsRouteLimits(t *testing.T) {
+	limiter, err := NewLimiter(&config.Config{
+		RateLimitDefault: "50/1s",
+		RateLimitRoutes:  "api=1000/1h, export=off",
+	}, NewMemoryStore())
+	assert.NoError(t, err)
+
+	limits := limiter.(*Options).limits
+	assert.Equal(t, Limit{Requests: 50, Period: time.Second}, limiter.(*Options).fallback)
+	assert.Equal(t, Limit{Requests: 1000, Period: time.Hour}, limits["api"])
+	assert.Equal(t, defaultRouteLimits["auth"], limits["auth"])
+	assert.True(t, limits["export"].disabled())
+
+	for _, routes := range []string{"api", "api=10", "api=ten/1m", "api=10/soon", "=10/1m"} {
+		_, err := NewLimiter(&config.Config{RateLimitRoutes: routes}, NewMemoryStore())
+		assert.Error(t, err, routes)
+	}
+}
+
+func TestLimiterKeysByUserOrIP(t *testing.T) {
+	limiter, err := NewLimiter(&config.Config{RateLimitRoutes: "api=1/1m"}, NewMemoryStore())
+	assert.NoError(t, err)
+
+	anonymous := httptest.NewRequest("GET", "/orders", nil)
+	anonymous.RemoteAddr = "10.0.0.1:5000"
+
+	user := httptest.NewRequest("GET", "/orders", nil)
+	user.RemoteAddr = "10.0.0.1:5000"
+	user = user.WithContext(principalAuth.NewContext(user.Context(), &principalAuth.Principal{UserID: 7}))
+
+	result, _ := limiter.Allow(anonymous, "api")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Allow(user, "api")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Allow(user, "api")
+	assert.False(t, result.Allowed)
+}
+
+func TestClientIPTrustsOnlyConfiguredProxies(t *testing.T) {
+	limiter, err := NewLimiter(&config.Config{RateLimitTrustedProxies: "10.0.0.0/8"}, NewMemoryStore())
+	assert.NoError(t, err)
+	opt := limiter.(*Options)
+
+	r := httptest.NewRequest("GET", "/auth/login", nil)
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.9, 10.0.0.2")
+
+	r.RemoteAddr = "10.0.0.1:5000"
+	assert.Equal(t, "203.0.113.9", opt.clientIP(r))
+
+	r.RemoteAddr = "198.51.100.4:5000"
+	assert.Equal(t, "198.51.100.4", opt.clientIP(r))
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store keeps the token buckets. The memory store suits a single instance,
+// instances sharing their limits need a Store backed by a shared database.
+type Store interface {
+	// Take removes a token from the bucket of key, created full for a new
+	// key, and reports whether there was one.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Result is the state of a bucket after a Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token when not allowed.
+	RetryAfter time.Duration
+}
+
+// sweepInterval is how often the memory store forgets full buckets.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+type memoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore keeps the buckets in the memory of this instance.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (m *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := m.now()
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity}
+		m.buckets[key] = b
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	}
+	b.updated = now
+	b.period = limit.Period
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep forgets the buckets refilled since their last use, they are the same
+// as a new one.
+func (m *memoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) >= b.period {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
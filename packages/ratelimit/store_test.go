@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreRefillsOverThePeriod(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := &memoryStore{now: func() time.Time { return now }, buckets: map[string]*bucket{}}
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+	ctx := context.Background()
+
+	first, _ := store.Take(ctx, "auth:ip:10.0.0.1", limit)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.Equal(t, 5*time.Second, first.Reset)
+
+	store.Take(ctx, "auth:ip:10.0.0.1", limit)
+	denied, _ := store.Take(ctx, "auth:ip:10.0.0.1", limit)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Equal(t, 5*time.Second, denied.RetryAfter)
+
+	// other clients have their own bucket
+	other, _ := store.Take(ctx, "auth:ip:10.0.0.2", limit)
+	assert.True(t, other.Allowed)
+
+	now = now.Add(5 * time.Second)
+	refilled, _ := store.Take(ctx, "auth:ip:10.0.0.1", limit)
+	assert.True(t, refilled.Allowed)
+}
+
+func TestMemoryStoreForgetsFullBuckets(t *testing.T) {
+	now := time.Unix(0, 0)
+	store := &memoryStore{now: func() time.Time { return now }, buckets: map[string]*bucket{}, lastSweep: now}
+	limit := Limit{Requests: 1, Period: time.Second}
+
+	store.Take(context.Background(), "api:user:1", limit)
+	assert.Len(t, store.buckets, 1)
+
+	now = now.Add(sweepInterval)
+	store.Take(context.Background(), "api:user:2", limit)
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "api:user:2")
+}
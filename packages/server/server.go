@@ -2,15 +2,22 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"time"
 
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	defaultReadTimeout  = 15 * time.Second
+	defaultWriteTimeout = 30 * time.Second
+	defaultIdleTimeout  = 60 * time.Second
 )
 
 type Server struct {
@@ -18,50 +25,84 @@ type Server struct {
 	Router *mux.Router
 }
 
+// NewServer times out reading a request after SERVER_HTTP_READ_TIMEOUT,
+// writing its response after SERVER_HTTP_WRITE_TIMEOUT and idle keep-alive
+// connections after SERVER_HTTP_IDLE_TIMEOUT, in seconds.
 func NewServer(cfg *config.Config) *Server {
 	r := mux.NewRouter()
 
+	// a span per request, continuing the trace of an incoming traceparent
+	r.Use(otelhttp.NewMiddleware("http.server", otelhttp.WithSpanNameFormatter(spanName)))
+
 	return &Server{
 		http: &http.Server{
-			Addr: cfg.PortHttpServer,
+			Addr:              cfg.PortHttpServer,
+			ReadTimeout:       seconds(cfg.ServerHTTPReadTimeout, defaultReadTimeout),
+			ReadHeaderTimeout: seconds(cfg.ServerHTTPReadTimeout, defaultReadTimeout),
+			WriteTimeout:      seconds(cfg.ServerHTTPWriteTimeout, defaultWriteTimeout),
+			IdleTimeout:       seconds(cfg.ServerHTTPIdleTimeout, defaultIdleTimeout),
 		},
 		Router: r,
 	}
 }
 
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+
+	return time.Duration(value) * time.Second
+}
+
+// spanName names request spans after the matched route template so requests
+// to different ids share a name.
+func spanName(operation string, r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return r.Method + " " + template
+		}
+	}
+
+	return r.Method + " " + operation
+}
+
 func (s *Server) RegisterRouter(handler http.Handler) {
 	s.http.Handler = handlers.CORS(
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "traceparent", "tracestate", "Idempotency-Key"}),
+		handlers.ExposedHeaders([]string{"Idempotent-Replayed", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
 		handlers.AllowedOrigins([]string{"*"}),
 		handlers.AllowCredentials())(handler)
 }
 
-func (s *Server) ListenAndServe() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
+// Start listens on PORT_HTTP_SERVER and serves in the background, a failure
+// of the listener after it started is passed to fail.
+func (s *Server) Start(fail func(error)) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
 
-	errc := make(chan error)
+	log.Printf("HTTP Server listen on %s\n", ln.Addr())
 	go func() {
-		log.Printf("HTTP Server listen on %s\n", s.http.Addr)
-		errc <- s.http.ListenAndServe()
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(err)
+		}
 	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	return nil
+}
 
-	select {
-	case err := <-errc:
-		log.Printf("Error when listen on %s\n", s.http.Addr)
+// Shutdown stops accepting connections and waits for the requests in flight,
+// connections still open when ctx is done are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Println("Shutting down the server...")
+	if err := s.http.Shutdown(ctx); err != nil {
+		log.Printf("Error when shutting down the server: %v\n", err)
+		s.http.Close()
 		return err
-	case <-quit:
-		log.Println("Shutting down the server...")
-		err := s.http.Shutdown(ctx)
-		if err != nil {
-			log.Printf("Error when shutting down the server: %v\n", err)
-			return err
-		}
-		log.Println("Server gracefully stopped")
-		return nil
 	}
+
+	log.Println("Server gracefully stopped")
+	return nil
 }
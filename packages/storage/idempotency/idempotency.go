@@ -0,0 +1,159 @@
+package idempotencyDatabase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// mysqlErrDuplicateEntry is the MySQL error number of unique key violations.
+	mysqlErrDuplicateEntry = 1062
+
+	// purgeBatchSize bounds the rows a purge deletes per statement.
+	purgeBatchSize = 1000
+)
+
+var ErrorKeyExists = errors.New("idempotency key already exists")
+
+// Record is a request stored under an idempotency key. StatusCode is zero
+// while the first request is still being processed.
+type Record struct {
+	Key          string
+	UserID       int64
+	RequestHash  string
+	StatusCode   int
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
+
+type Idempotency interface {
+	// Get returns the unexpired record stored under key, or nil.
+	Get(ctx context.Context, userID int64, key string) (*Record, error)
+	// Reserve stores a record without a response. It returns ErrorKeyExists
+	// when another request already holds the key.
+	Reserve(ctx context.Context, record *Record) error
+	// Complete stores the response of a reserved record.
+	Complete(ctx context.Context, userID int64, key string, statusCode int, body []byte) error
+	// Release removes a record so the request can be retried.
+	Release(ctx context.Context, userID int64, key string) error
+	// Purge deletes the expired records and returns how many there were.
+	Purge(ctx context.Context) (int64, error)
+}
+
+type Options struct {
+	db  *sql.DB
+	log log.Log
+}
+
+func NewIdempotency(db *sql.DB, lg log.Log) Idempotency {
+	opt := new(Options)
+	opt.db = db
+	opt.log = lg
+
+	return opt
+}
+
+func (o *Options) Get(ctx context.Context, userID int64, key string) (*Record, error) {
+	record := Record{}
+	var statusCode sql.NullInt64
+
+	query := "SELECT idempotency_key, user_id, request_hash, status_code, response_body, expires_at FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND expires_at > ?"
+	err := o.db.QueryRowContext(ctx, query, userID, key, time.Now()).Scan(&record.Key, &record.UserID, &record.RequestHash, &statusCode, &record.ResponseBody, &record.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return nil, err
+	}
+	record.StatusCode = int(statusCode.Int64)
+
+	return &record, nil
+}
+
+func (o *Options) Reserve(ctx context.Context, record *Record) error {
+	// An expired record no longer holds its key, the others are left to Purge
+	_, err := o.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ? AND expires_at <= ?", record.UserID, record.Key, time.Now())
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	_, err = o.db.ExecContext(ctx, "INSERT INTO idempotency_keys (idempotency_key, user_id, request_hash, expires_at) VALUES (?, ?, ?, ?)",
+		record.Key, record.UserID, record.RequestHash, record.ExpiresAt)
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return ErrorKeyExists
+	}
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) Complete(ctx context.Context, userID int64, key string, statusCode int, body []byte) error {
+	_, err := o.db.ExecContext(ctx, "UPDATE idempotency_keys SET status_code = ?, response_body = ? WHERE user_id = ? AND idempotency_key = ?", statusCode, body, userID, key)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) Release(ctx context.Context, userID int64, key string) error {
+	_, err := o.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?", userID, key)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) Purge(ctx context.Context) (int64, error) {
+	var purged int64
+	for {
+		result, err := o.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ? LIMIT ?", time.Now(), purgeBatchSize)
+		if err != nil {
+			o.log.ErrorLog(ctx, err)
+			return purged, err
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			o.log.ErrorLog(ctx, err)
+			return purged, err
+		}
+		purged += deleted
+
+		if deleted < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// PurgeEvery purges the expired records of idempotency every interval until
+// ctx is cancelled.
+func PurgeEvery(idempotency Idempotency, interval time.Duration) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+
+			// failures are logged, the next run tries again
+			idempotency.Purge(ctx)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/ahsansandiah/dpo-test/packages/config"
 	"github.com/cenkalti/backoff"
 	_ "github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
 type MySQL interface {
@@ -31,7 +33,15 @@ func NewMySQL(cfg *config.Config) *Options {
 }
 
 func (o *Options) Connect() (*sql.DB, error) {
-	database, err := sql.Open(o.driver, o.dns)
+	// every query becomes a span under the span of its context
+	database, err := otelsql.Open(o.driver, o.dns,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,82 @@
+package transactionDatabase
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+)
+
+// Executor runs queries, it is satisfied by both *sql.DB and *sql.Tx.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type Transaction interface {
+	// WithinTransaction runs fn with a context carrying a transaction. The
+	// transaction commits when fn returns nil and rolls back otherwise. When
+	// ctx already carries a transaction fn joins it.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Executor returns the transaction carried by ctx, or the database when
+	// there is none.
+	Executor(ctx context.Context) Executor
+}
+
+type Options struct {
+	db  *sql.DB
+	log log.Log
+}
+
+var contextKeyTx = config.ContextKey("tx")
+
+func NewTransaction(db *sql.DB, lg log.Log) Transaction {
+	opt := new(Options)
+	opt.db = db
+	opt.log = lg
+
+	return opt
+}
+
+func (o *Options) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(contextKeyTx).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		o.log.ErrorLog(ctx, err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				o.log.ErrorLog(ctx, rollbackErr)
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			o.log.ErrorLog(ctx, err)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, contextKeyTx, tx))
+}
+
+func (o *Options) Executor(ctx context.Context) Executor {
+	if tx, ok := ctx.Value(contextKeyTx).(*sql.Tx); ok {
+		return tx
+	}
+
+	return o.db
+}
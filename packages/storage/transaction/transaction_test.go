@@ -0,0 +1,53 @@
+package transactionDatabase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ahsansandiah/dpo-test/packages/log"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinTransactionCommits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	trx := NewTransaction(db, log.NewMockLog(gomock.NewController(t)))
+	err = trx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		_, err := trx.Executor(ctx).ExecContext(ctx, "INSERT INTO orders (id) VALUES (1)")
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTransactionRollsBackAndJoinsOuterTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	failure := errors.New("failure")
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	trx := NewTransaction(db, log.NewMockLog(gomock.NewController(t)))
+	err = trx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		return trx.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.NotEqual(t, db, trx.Executor(ctx))
+			return failure
+		})
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, db, trx.Executor(context.Background()))
+}
@@ -0,0 +1,124 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ahsansandiah/dpo-test/packages/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOtlp   = "otlp"
+
+	defaultServiceName = "dpo-test"
+)
+
+type Telemetry interface {
+	Shutdown(ctx context.Context) error
+}
+
+type Options struct {
+	provider *sdktrace.TracerProvider
+	closer   io.Closer
+}
+
+// NewTelemetry installs the global tracer provider and the W3C trace context
+// propagator. With no exporter spans are not recorded, but incoming trace
+// context is still passed on to outbound calls and logs.
+func NewTelemetry(cfg *config.Config) (Telemetry, error) {
+	opt := new(Options)
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := opt.exporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return opt, nil
+	}
+
+	serviceName := cfg.TraceServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	ratio := cfg.TraceSampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	opt.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.DeploymentEnvironment(cfg.AppEnv),
+		)),
+	)
+	otel.SetTracerProvider(opt.provider)
+
+	return opt, nil
+}
+
+func (o *Options) exporter(cfg *config.Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TraceExporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		if cfg.TraceFile == "" {
+			return nil, fmt.Errorf("TRACE_FILE is required with TRACE_EXPORTER=%s", ExporterFile)
+		}
+
+		file, err := os.OpenFile(cfg.TraceFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		o.closer = file
+
+		return stdouttrace.New(stdouttrace.WithWriter(file))
+	case ExporterOtlp:
+		opts := []otlptracehttp.Option{}
+		if cfg.TraceOtlpEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.TraceOtlpEndpoint))
+		}
+		if cfg.TraceOtlpInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+
+	return nil, fmt.Errorf("unknown TRACE_EXPORTER %q", cfg.TraceExporter)
+}
+
+// Shutdown flushes the spans still buffered.
+func (o *Options) Shutdown(ctx context.Context) error {
+	if o.provider == nil {
+		return nil
+	}
+
+	err := o.provider.Shutdown(ctx)
+	if o.closer != nil {
+		if closeErr := o.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}